@@ -6,8 +6,11 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"log"
+	"manajemen_tugas_master/helper"
 	"manajemen_tugas_master/model/domain"
 	"os"
+	"strings"
+	"time"
 )
 
 func ConnectDB() (*gorm.DB, error) {
@@ -20,13 +23,328 @@ func ConnectDB() (*gorm.DB, error) {
 		return nil, fmt.Errorf("Failed to connect to the database: %v", err)
 	}
 
+	// user lama yang dibuat sebelum ada verifikasi email dianggap sudah terverifikasi
+	verifyExistingUsers := db.Migrator().HasTable(&domain.User{}) && !db.Migrator().HasColumn(&domain.User{}, "Verified")
+
+	// due date lama berupa string, kolom lama di-rename agar AutoMigrate membuat kolom datetime yang baru
+	if err := renameStringDueDates(db); err != nil {
+		return nil, err
+	}
+
 	log.Println("Running migrations")
 	if err := db.AutoMigrate(
 		&domain.User{},
 		&domain.Task{},
+		&domain.RefreshToken{},
+		&domain.RevokedToken{},
+		&domain.PasswordReset{},
+		&domain.EmailVerification{},
+		&domain.Workspace{},
+		&domain.WorkspaceMember{},
+		&domain.TaskInvitation{},
+		&domain.TaskMember{},
+		&domain.TaskDependency{},
+		&domain.Comment{},
+		&domain.CommentAttachment{},
+		&domain.Mention{},
+		&domain.Notification{},
+		&domain.Label{},
+		&domain.CustomField{},
+		&domain.CustomFieldValue{},
+		&domain.ChecklistItem{},
+		&domain.TaskRecurrence{},
+		&domain.TaskTemplate{},
+		&domain.PlanningApproval{},
+		&domain.ProjectReview{},
+		&domain.TaskActivity{},
 	); err != nil {
 		return nil, err
 	}
 
+	if verifyExistingUsers {
+		log.Println("Marking existing users as verified")
+		if err := db.Exec("UPDATE users SET verified = ?, verified_at = NOW()", true).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// admin pertama ditentukan dari environment variable ADMIN_EMAIL
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		if err := db.Model(&domain.User{}).Where("email = ?", adminEmail).Update("role", domain.UserRoleAdmin).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := migrateDefaultWorkspace(db); err != nil {
+		return nil, err
+	}
+
+	if err := migrateTaskMembers(db); err != nil {
+		return nil, err
+	}
+
+	if err := migrateProjectComments(db); err != nil {
+		return nil, err
+	}
+
+	if err := migrateWorkflowStatuses(db); err != nil {
+		return nil, err
+	}
+
+	// dijalankan setiap start selama kolom *_old masih ada, agar nilai yang gagal dibaca bisa diperbaiki lalu dicoba lagi
+	if err := migrateStringDueDates(db); err != nil {
+		return nil, err
+	}
+
 	return db, err
 }
+
+// migrateDefaultWorkspace memindahkan task lama yang belum memiliki workspace ke workspace "Default" yang berisi semua user
+func migrateDefaultWorkspace(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&domain.Task{}).Where("workspace_id = 0 OR workspace_id IS NULL").Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	log.Println("Moving existing tasks to the default workspace")
+	return db.Transaction(func(tx *gorm.DB) error {
+		workspace := domain.Workspace{Name: "Default"}
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+
+		// admin global menjadi admin workspace, user lain menjadi member
+		if err := tx.Exec(`INSERT INTO workspace_members (workspace_id, user_id, email, role, created_at, updated_at)
+			SELECT ?, id, email, IF(role = 'admin', 'admin', 'member'), NOW(), NOW() FROM users`, workspace.ID).Error; err != nil {
+			return err
+		}
+
+		// jika belum ada admin global, user pertama dijadikan admin workspace
+		var countAdmin int64
+		if err := tx.Model(&domain.WorkspaceMember{}).Where("workspace_id = ? AND role = ?", workspace.ID, domain.WorkspaceRoleAdmin).Count(&countAdmin).Error; err != nil {
+			return err
+		}
+		if countAdmin == 0 {
+			if err := tx.Exec("UPDATE workspace_members SET role = ? WHERE workspace_id = ? ORDER BY user_id LIMIT 1", domain.WorkspaceRoleAdmin, workspace.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&domain.Task{}).Where("workspace_id = 0 OR workspace_id IS NULL").Update("workspace_id", workspace.ID).Error
+	})
+}
+
+// migrateTaskMembers memindahkan data dari tabel owners, managers dan employees lama ke tabel task_members
+func migrateTaskMembers(db *gorm.DB) error {
+	if !db.Migrator().HasTable("owners") {
+		return nil
+	}
+
+	log.Println("Moving owners, managers and employees to task members")
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT IGNORE INTO task_members (task_id, user_id, email, role, created_at, updated_at)
+			SELECT tasks.id, owners.user_id, owners.email, ?, owners.created_at, owners.updated_at
+			FROM tasks JOIN owners ON owners.id = tasks.owner_id`, domain.TaskRoleOwner).Error; err != nil {
+			return err
+		}
+
+		// user yang sudah menjadi owner tidak ikut dipindahkan sebagai manager atau employee
+		if tx.Migrator().HasTable("task_managers") {
+			if err := tx.Exec(`INSERT IGNORE INTO task_members (task_id, user_id, email, role, created_at, updated_at)
+				SELECT task_managers.task_id, managers.user_id, managers.email, ?, managers.created_at, managers.updated_at
+				FROM task_managers JOIN managers ON managers.id = task_managers.manager_id`, domain.TaskRoleManager).Error; err != nil {
+				return err
+			}
+		}
+
+		if tx.Migrator().HasTable("task_employees") {
+			if err := tx.Exec(`INSERT IGNORE INTO task_members (task_id, user_id, email, role, created_at, updated_at)
+				SELECT task_employees.task_id, employees.user_id, employees.email, ?, employees.created_at, employees.updated_at
+				FROM task_employees JOIN employees ON employees.id = task_employees.employee_id`, domain.TaskRoleEmployee).Error; err != nil {
+				return err
+			}
+		}
+
+		if tx.Migrator().HasConstraint("tasks", "fk_tasks_owner") {
+			if err := tx.Exec("ALTER TABLE tasks DROP FOREIGN KEY fk_tasks_owner").Error; err != nil {
+				return err
+			}
+		}
+		if tx.Migrator().HasColumn("tasks", "owner_id") {
+			if err := tx.Migrator().DropColumn("tasks", "owner_id"); err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().DropTable("task_managers", "task_employees", "managers", "employees", "owners")
+	})
+}
+
+// renameStringDueDates me-rename kolom due date bertipe string menjadi *_old
+func renameStringDueDates(db *gorm.DB) error {
+	if !db.Migrator().HasTable("tasks") {
+		return nil
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes("tasks")
+	if err != nil {
+		return err
+	}
+
+	for _, columnType := range columnTypes {
+		name := columnType.Name()
+		if name != "planning_due_date" && name != "project_due_date" {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(columnType.DatabaseTypeName()), "char") {
+			continue
+		}
+		if err := db.Migrator().RenameColumn("tasks", name, name+"_old"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyDueDateLayouts berisi format tanggal lama yang hanya diterima saat migrasi, dd/mm/yyyy dengan hari dan bulan boleh satu digit
+var legacyDueDateLayouts = []string{
+	"2/1/2006",
+}
+
+// parseLegacyDueDate membaca due date string lama, selain format ISO-8601 juga menerima format dd/mm/yyyy
+func parseLegacyDueDate(value string) (time.Time, error) {
+	dueDate, err := helper.ParseDueDate(value)
+	if err == nil {
+		return dueDate, nil
+	}
+	for _, layout := range legacyDueDateLayouts {
+		if dueDate, layoutErr := time.Parse(layout, strings.TrimSpace(value)); layoutErr == nil {
+			return dueDate, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// migrateStringDueDates mengubah due date string lama menjadi datetime, kolom *_old hanya dihapus jika semua nilai
+// berhasil dibaca sehingga nilai yang gagal tidak hilang dan bisa diperbaiki manual
+func migrateStringDueDates(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, column := range []string{"planning_due_date", "project_due_date"} {
+			oldColumn := column + "_old"
+			if !tx.Migrator().HasColumn("tasks", oldColumn) {
+				continue
+			}
+			log.Printf("Converting task %s to datetime", column)
+
+			// baris yang sudah terisi tidak ditimpa, agar due date yang diubah setelah migrasi sebelumnya tetap
+			var rows []struct {
+				ID      uint64
+				DueDate string
+			}
+			if err := tx.Table("tasks").Select("id, " + oldColumn + " AS due_date").
+				Where(oldColumn + " <> '' AND " + column + " IS NULL").Scan(&rows).Error; err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, row := range rows {
+				dueDate, err := parseLegacyDueDate(row.DueDate)
+				if err != nil {
+					log.Printf("Task %d: cannot parse %s %q", row.ID, column, row.DueDate)
+					failed++
+					continue
+				}
+				if err := tx.Table("tasks").Where("id = ?", row.ID).Update(column, dueDate.UTC()).Error; err != nil {
+					return err
+				}
+			}
+
+			if failed > 0 {
+				log.Printf("Keeping tasks.%s, %d value(s) could not be converted", oldColumn, failed)
+				continue
+			}
+			if err := tx.Migrator().DropColumn("tasks", oldColumn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateProjectComments memindahkan isi kolom tasks.project_comment lama menjadi comment,
+// penulisnya diambil dari employee task, jika tidak ada dari manager atau owner, lalu dari admin workspace task
+func migrateProjectComments(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("tasks", "project_comment") {
+		return nil
+	}
+
+	log.Println("Moving project comments to comments")
+	return db.Transaction(func(tx *gorm.DB) error {
+		author := tx.Raw(`COALESCE(
+				(SELECT task_members.user_id FROM task_members WHERE task_members.task_id = tasks.id
+					ORDER BY FIELD(task_members.role, ?, ?, ?), task_members.id LIMIT 1),
+				(SELECT workspace_members.user_id FROM workspace_members WHERE workspace_members.workspace_id = tasks.workspace_id
+					ORDER BY FIELD(workspace_members.role, ?, ?), workspace_members.id LIMIT 1))`,
+			domain.TaskRoleEmployee, domain.TaskRoleManager, domain.TaskRoleOwner,
+			domain.WorkspaceRoleAdmin, domain.WorkspaceRoleMember)
+
+		if err := tx.Exec(`INSERT INTO comments (task_id, author_id, body, created_at, updated_at)
+			SELECT legacy.task_id, legacy.author_id, legacy.body, legacy.updated_at, legacy.updated_at
+			FROM (SELECT tasks.id AS task_id, ? AS author_id, tasks.project_comment AS body, tasks.updated_at
+				FROM tasks WHERE tasks.project_comment IS NOT NULL AND tasks.project_comment <> '') AS legacy
+			WHERE legacy.author_id IS NOT NULL`, author).Error; err != nil {
+			return err
+		}
+
+		// comment yang sudah dipindahkan dikosongkan agar tidak disalin dua kali jika migrasi dijalankan ulang
+		if err := tx.Exec(`UPDATE tasks SET project_comment = NULL
+			WHERE project_comment IS NOT NULL AND project_comment <> '' AND ? IS NOT NULL`, author).Error; err != nil {
+			return err
+		}
+
+		// kolom tidak dihapus selama masih ada comment yang tidak memiliki penulis
+		var remaining int64
+		if err := tx.Table("tasks").Where("project_comment IS NOT NULL AND project_comment <> ''").Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 0 {
+			log.Printf("Keeping tasks.project_comment, %d comment(s) have no task or workspace member to use as author", remaining)
+			return nil
+		}
+
+		return tx.Migrator().DropColumn("tasks", "project_comment")
+	})
+}
+
+// migrateWorkflowStatuses menambahkan status workflow baru pada kolom enum, AutoMigrate tidak mengubah isi enum yang sudah ada
+func migrateWorkflowStatuses(db *gorm.DB) error {
+	columnTypes, err := db.Migrator().ColumnTypes(&domain.Task{})
+	if err != nil {
+		return err
+	}
+
+	newStatuses := map[string]string{
+		"planning_status": domain.PlanningStatusSubmitted,
+		"project_status":  domain.ProjectStatusInReview,
+	}
+	for _, columnType := range columnTypes {
+		status, ok := newStatuses[columnType.Name()]
+		if !ok {
+			continue
+		}
+		fullType, _ := columnType.ColumnType()
+		if strings.Contains(fullType, "'"+status+"'") {
+			continue
+		}
+		log.Printf("Adding workflow statuses to tasks.%s", columnType.Name())
+		if err := db.Migrator().AlterColumn(&domain.Task{}, columnType.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
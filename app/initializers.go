@@ -13,8 +13,8 @@ func InitializeRepositoryUser(db *gorm.DB) (repository.UserRepository, error) {
 	return repository.NewUserRepository(db), nil
 }
 
-func InitializeServiceUser(userRepository repository.UserRepository) (service.UserService, error) {
-	return service.NewUserService(userRepository, validator.New()), nil
+func InitializeServiceUser(userRepository repository.UserRepository, taskAndOwnerRepository repository.TaskAndOwnerRepository) (service.UserService, error) {
+	return service.NewUserService(userRepository, taskAndOwnerRepository, validator.New()), nil
 }
 
 func InitializeControllerUser(userService service.UserService) (controller.UserController, error) {
@@ -26,9 +26,53 @@ func InitializeRepositoryTask(db *gorm.DB) (repository.TaskAndOwnerRepository, e
 	return repository.NewTaskAndOwnerRepository(db), nil
 }
 
-func InitializeServiceTask(taskAndOwnerRepository repository.TaskAndOwnerRepository) (service.TaskAndOwnerService, error) {
-	return service.NewTaskAndOwnerService(taskAndOwnerRepository, validator.New()), nil
+func InitializeServiceTask(taskAndOwnerRepository repository.TaskAndOwnerRepository, workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository, activityRepository repository.ActivityRepository, notificationService service.NotificationService) (service.TaskAndOwnerService, error) {
+	return service.NewTaskAndOwnerService(taskAndOwnerRepository, workspaceRepository, userRepository, activityRepository, notificationService, validator.New()), nil
 }
 func InitializeControllerTask(taskAndOwnerService service.TaskAndOwnerService) (controller.TaskAndOwnerController, error) {
 	return *controller.NewTaskController(taskAndOwnerService), nil
 }
+
+// workspace
+func InitializeRepositoryWorkspace(db *gorm.DB) (repository.WorkspaceRepository, error) {
+	return repository.NewWorkspaceRepository(db), nil
+}
+
+func InitializeServiceWorkspace(workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository, activityRepository repository.ActivityRepository) (service.WorkspaceService, error) {
+	return service.NewWorkspaceService(workspaceRepository, userRepository, activityRepository, validator.New()), nil
+}
+
+func InitializeControllerWorkspace(workspaceService service.WorkspaceService) (controller.WorkspaceController, error) {
+	return *controller.NewWorkspaceController(workspaceService), nil
+}
+
+// comment
+func InitializeRepositoryComment(db *gorm.DB) (repository.CommentRepository, error) {
+	return repository.NewCommentRepository(db), nil
+}
+
+func InitializeServiceComment(commentRepository repository.CommentRepository, taskAndOwnerRepository repository.TaskAndOwnerRepository, notificationService service.NotificationService) (service.CommentService, error) {
+	return service.NewCommentService(commentRepository, taskAndOwnerRepository, notificationService, validator.New()), nil
+}
+
+func InitializeControllerComment(commentService service.CommentService) (controller.CommentController, error) {
+	return *controller.NewCommentController(commentService), nil
+}
+
+// notification
+func InitializeRepositoryNotification(db *gorm.DB) (repository.NotificationRepository, error) {
+	return repository.NewNotificationRepository(db), nil
+}
+
+func InitializeServiceNotification(notificationRepository repository.NotificationRepository, userRepository repository.UserRepository) (service.NotificationService, error) {
+	return service.NewNotificationService(notificationRepository, userRepository), nil
+}
+
+func InitializeControllerNotification(notificationService service.NotificationService) (controller.NotificationController, error) {
+	return *controller.NewNotificationController(notificationService), nil
+}
+
+// activity
+func InitializeRepositoryActivity(db *gorm.DB) (repository.ActivityRepository, error) {
+	return repository.NewActivityRepository(db), nil
+}
@@ -8,25 +8,75 @@ import (
 )
 
 func SetupRoutes(app *fiber.App, db *gorm.DB) {
-	// user initialize
+	// repository initialize
 	userRepository, _ := InitializeRepositoryUser(db)
-	userService, _ := InitializeServiceUser(userRepository)
+	workspaceRepository, _ := InitializeRepositoryWorkspace(db)
+	taskRepository, _ := InitializeRepositoryTask(db)
+	commentRepository, _ := InitializeRepositoryComment(db)
+	notificationRepository, _ := InitializeRepositoryNotification(db)
+	activityRepository, _ := InitializeRepositoryActivity(db)
+
+	// notification initialize, dipakai oleh service lain untuk mengirim notifikasi
+	notificationService, _ := InitializeServiceNotification(notificationRepository, userRepository)
+	notificationController, _ := InitializeControllerNotification(notificationService)
+
+	// user initialize
+	userService, _ := InitializeServiceUser(userRepository, taskRepository)
 	userController, _ := InitializeControllerUser(userService)
 
+	// workspace initialize
+	workspaceService, _ := InitializeServiceWorkspace(workspaceRepository, userRepository, activityRepository)
+	workspaceController, _ := InitializeControllerWorkspace(workspaceService)
+
 	// task initialize
-	taskRepository, _ := InitializeRepositoryTask(db)
-	taskService, _ := InitializeServiceTask(taskRepository)
+	taskService, _ := InitializeServiceTask(taskRepository, workspaceRepository, userRepository, activityRepository, notificationService)
 	taskController, _ := InitializeControllerTask(taskService)
+	StartRecurrenceScheduler(taskService)
+
+	// comment initialize
+	commentService, _ := InitializeServiceComment(commentRepository, taskRepository, notificationService)
+	commentController, _ := InitializeControllerComment(commentService)
 
 	// Group route untuk user
 	userRoutes := app.Group("/")
 	userRoutes.Post("user/signup", userController.SignupUser)
 	userRoutes.Post("user/login", userController.LoginUser)
-	userRoutes.Get("users", userController.GetAllUsers)
-	userRoutes.Get("user/:id", userController.GetUserByID)
-	userRoutes.Delete("user/:id", userController.DeleteUser)
+	userRoutes.Post("user/refresh", userController.RefreshToken)
+	userRoutes.Post("user/password/forgot", userController.ForgotPassword)
+	userRoutes.Post("user/password/reset", userController.ResetPassword)
+	userRoutes.Get("user/verify", userController.VerifyEmail)
+	userRoutes.Post("user/verify/resend", userController.ResendVerification)
 	userRoutes.Use(middleware.AuthUser(userService)) // Gunakan middleware untuk semua route dalam grup user
+	userRoutes.Get("users", middleware.AdminOnly(), userController.GetAllUsers)
+	userRoutes.Get("user/:id", userController.GetUserByID)
 	userRoutes.Put("user/:id", userController.UpdateUser)
+	userRoutes.Put("user/:id/role", middleware.AdminOnly(), userController.UpdateUserRole)
+	userRoutes.Delete("user/:id", userController.DeleteUser)
+	userRoutes.Post("user/logout", userController.LogoutUser)
+
+	// Group route untuk workspace
+	workspaceRoutes := app.Group("/")
+	workspaceRoutes.Use(middleware.AuthUser(userService))
+	workspaceRoutes.Post("workspace", workspaceController.CreateWorkspace)
+	workspaceRoutes.Get("workspaces", workspaceController.GetAllWorkspaces)
+	workspaceRoutes.Get("workspace/:id", workspaceController.GetWorkspaceById)
+	workspaceRoutes.Put("workspace/:id", workspaceController.UpdateWorkspace)
+	workspaceRoutes.Post("workspace/:id/member", workspaceController.AddMember)
+	workspaceRoutes.Delete("workspace/:id/member/:user_id", workspaceController.DeleteMember)
+	workspaceRoutes.Get("workspace/:id/labels", workspaceController.GetAllLabels)
+	workspaceRoutes.Post("workspace/:id/label", workspaceController.CreateLabel)
+	workspaceRoutes.Put("workspace/:id/label/:label_id", workspaceController.UpdateLabel)
+	workspaceRoutes.Delete("workspace/:id/label/:label_id", workspaceController.DeleteLabel)
+	workspaceRoutes.Get("workspace/:id/custom_fields", workspaceController.GetAllCustomFields)
+	workspaceRoutes.Post("workspace/:id/custom_field", workspaceController.CreateCustomField)
+	workspaceRoutes.Put("workspace/:id/custom_field/:field_id", workspaceController.UpdateCustomField)
+	workspaceRoutes.Delete("workspace/:id/custom_field/:field_id", workspaceController.DeleteCustomField)
+	workspaceRoutes.Get("workspace/:id/activity", workspaceController.GetActivity)
+	workspaceRoutes.Get("workspace/:id/templates", workspaceController.GetAllTaskTemplates)
+	workspaceRoutes.Post("workspace/:id/template", workspaceController.CreateTaskTemplate)
+	workspaceRoutes.Get("workspace/:id/template/:template_id", workspaceController.GetTaskTemplate)
+	workspaceRoutes.Put("workspace/:id/template/:template_id", workspaceController.UpdateTaskTemplate)
+	workspaceRoutes.Delete("workspace/:id/template/:template_id", workspaceController.DeleteTaskTemplate)
 
 	// Group route untuk task
 	taskRoutes := app.Group("/")
@@ -35,14 +85,60 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
 	taskRoutes.Put("task/:id", taskController.UpdateTaskAndOwner)
 	taskRoutes.Get("task/:id", taskController.GetTaskAndOwnerById)
 	taskRoutes.Get("tasks", taskController.GetAllTasksAndOwners)
+	taskRoutes.Get("me/tasks", taskController.GetMyTasks)
 	taskRoutes.Get("tasks/owners", taskController.GetAllOwners)
 	taskRoutes.Get("tasks/managers", taskController.GetAllManagers)
 	taskRoutes.Get("tasks/employees", taskController.GetAllEmployees)
+	taskRoutes.Get("tasks/members", taskController.GetAllMembers)
 	taskRoutes.Get("tasks/planning_files", taskController.GetAllPlanningFiles)
 	taskRoutes.Get("tasks/project_files", taskController.GetAllProjectFiles)
-	taskRoutes.Delete("task/:id/manager/:manager_id", taskController.DeleteManager)
-	taskRoutes.Delete("task/:id/employee/:employee_id", taskController.DeleteEmployee)
+	taskRoutes.Post("task/:id/subtask", taskController.CreateSubtask)
+	taskRoutes.Get("task/:id/subtasks", taskController.GetSubtasks)
+	taskRoutes.Put("task/:id/subtasks/order", taskController.ReorderSubtasks)
+	taskRoutes.Get("task/:id/dependencies", taskController.GetDependencies)
+	taskRoutes.Post("task/:id/dependency", taskController.AddDependency)
+	taskRoutes.Delete("task/:id/dependency/:blocked_by_id", taskController.DeleteDependency)
+	taskRoutes.Get("task/:id/comments", commentController.GetAllComments)
+	taskRoutes.Post("task/:id/comments", commentController.CreateComment)
+	taskRoutes.Put("task/:id/comments/:comment_id", commentController.UpdateComment)
+	taskRoutes.Delete("task/:id/comments/:comment_id", commentController.DeleteComment)
+	taskRoutes.Post("task/:id/label", taskController.AttachLabel)
+	taskRoutes.Delete("task/:id/label/:label_id", taskController.DetachLabel)
+	taskRoutes.Put("task/:id/custom_field/:field_id", taskController.SetCustomFieldValue)
+	taskRoutes.Delete("task/:id/custom_field/:field_id", taskController.DeleteCustomFieldValue)
+	taskRoutes.Get("task/:id/checklist", taskController.GetChecklist)
+	taskRoutes.Post("task/:id/checklist", taskController.CreateChecklistItem)
+	taskRoutes.Put("task/:id/checklist/order", taskController.ReorderChecklist)
+	taskRoutes.Put("task/:id/checklist/:item_id", taskController.UpdateChecklistItem)
+	taskRoutes.Delete("task/:id/checklist/:item_id", taskController.DeleteChecklistItem)
+	taskRoutes.Get("task/:id/activity", taskController.GetTaskActivity)
+	taskRoutes.Get("task/:id/planning/approvals", taskController.GetPlanningApprovals)
+	taskRoutes.Post("task/:id/planning/submit", taskController.SubmitPlanning)
+	taskRoutes.Post("task/:id/planning/approve", taskController.ApprovePlanning)
+	taskRoutes.Post("task/:id/planning/reject", taskController.RejectPlanning)
+	taskRoutes.Get("task/:id/reviews", taskController.GetProjectReviews)
+	taskRoutes.Post("task/:id/review/submit", taskController.SubmitProjectReview)
+	taskRoutes.Post("task/:id/review/accept", taskController.AcceptProjectReview)
+	taskRoutes.Post("task/:id/review/request_changes", taskController.RequestProjectChanges)
+	taskRoutes.Put("task/:id/recurrence", taskController.SetRecurrence)
+	taskRoutes.Delete("task/:id/recurrence", taskController.DeleteRecurrence)
+	taskRoutes.Get("task/:id/invitations", taskController.GetAllInvitations)
+	taskRoutes.Delete("task/:id/invitation/:invitation_id", taskController.DeleteInvitation)
+	taskRoutes.Get("task/:id/members", taskController.GetAllTaskMembers)
+	taskRoutes.Post("task/:id/member", taskController.AddMember)
+	taskRoutes.Put("task/:id/member/:member_id", taskController.UpdateMemberRole)
+	taskRoutes.Put("task/:id/owner", taskController.TransferOwnership)
+	taskRoutes.Delete("task/:id/member/:member_id", taskController.DeleteMember)
+	taskRoutes.Delete("task/:id/manager/:member_id", taskController.DeleteMember)
+	taskRoutes.Delete("task/:id/employee/:member_id", taskController.DeleteMember)
 	taskRoutes.Delete("task/:id/planning_file/:file_id", taskController.DeletePlanningFile)
 	taskRoutes.Delete("task/:id/project_file/:file_id", taskController.DeleteProjectFile)
 	taskRoutes.Delete("task/:id", taskController.DeleteTaskAndOwner)
+
+	// Group route untuk notification
+	notificationRoutes := app.Group("/")
+	notificationRoutes.Use(middleware.AuthUser(userService))
+	notificationRoutes.Get("notifications", notificationController.GetAllNotifications)
+	notificationRoutes.Put("notifications/read", notificationController.MarkAllRead)
+	notificationRoutes.Put("notification/:id/read", notificationController.MarkRead)
 }
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"log"
+	"manajemen_tugas_master/service"
+	"os"
+	"time"
+)
+
+const defaultRecurrenceSchedulerInterval = time.Minute
+
+// StartRecurrenceScheduler menjalankan pembuatan task berulang di background,
+// interval bisa diatur melalui environment variable RECURRENCE_SCHEDULER_INTERVAL (contoh: 30s, 5m)
+func StartRecurrenceScheduler(taskService service.TaskAndOwnerService) {
+	interval := defaultRecurrenceSchedulerInterval
+	if value := os.Getenv("RECURRENCE_SCHEDULER_INTERVAL"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			log.Printf("Invalid RECURRENCE_SCHEDULER_INTERVAL %q, using %s", value, interval)
+		} else {
+			interval = duration
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			created, err := taskService.RunDueRecurrences(now.UTC())
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if created > 0 {
+				log.Printf("Created %d recurring task(s)", created)
+			}
+		}
+	}()
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"manajemen_tugas_master/helper"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/model/web"
+	"manajemen_tugas_master/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommentController struct {
+	commentService service.CommentService
+}
+
+func NewCommentController(commentService service.CommentService) *CommentController {
+	return &CommentController{commentService}
+}
+
+// uploadAttachments mengunggah semua file pada form "attachments" ke S3
+func uploadAttachments(ctx *fiber.Ctx) ([]domain.CommentAttachment, error) {
+	var attachments []domain.CommentAttachment
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		// request tanpa multipart form tidak memiliki attachment
+		return attachments, nil
+	}
+
+	for _, file := range form.File["attachments"] {
+		fileUrl, fileName, err := helper.SetupS3Uploader(file)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, domain.CommentAttachment{FileUrl: fileUrl, FileName: fileName})
+	}
+
+	return attachments, nil
+}
+
+func (c *CommentController) CreateComment(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	var comment domain.Comment
+	comment.Body = ctx.FormValue("body")
+
+	// parent_id diisi jika comment merupakan balasan
+	if parentId := ctx.FormValue("parent_id"); parentId != "" {
+		parentIdUint64, err := strconv.ParseUint(parentId, 10, 64)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid parent Id"})
+		}
+		comment.ParentID = &parentIdUint64
+	}
+
+	comment.Attachments, err = uploadAttachments(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error uploading attachment" + err.Error()})
+	}
+
+	commentDB, err := c.commentService.CreateComment(uint(taskIdUint64), uint(userID), &comment)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    commentDB,
+	})
+}
+
+func (c *CommentController) GetAllComments(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	comments, err := c.commentService.FindAllComments(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    comments,
+	})
+}
+
+func (c *CommentController) UpdateComment(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	commentId := ctx.Params("comment_id")
+	commentIdUint64, err := strconv.ParseUint(commentId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comment Id"})
+	}
+
+	attachments, err := uploadAttachments(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error uploading attachment" + err.Error()})
+	}
+
+	comment, err := c.commentService.UpdateComment(uint(taskIdUint64), uint(commentIdUint64), uint(userID), ctx.FormValue("body"), attachments)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    comment,
+	})
+}
+
+func (c *CommentController) DeleteComment(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	commentId := ctx.Params("comment_id")
+	commentIdUint64, err := strconv.ParseUint(commentId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comment Id"})
+	}
+
+	if err := c.commentService.DeleteComment(uint(taskIdUint64), uint(commentIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Comment deleted successfully"})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/model/web"
+	"manajemen_tugas_master/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationController struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationController(notificationService service.NotificationService) *NotificationController {
+	return &NotificationController{notificationService}
+}
+
+func (n *NotificationController) GetAllNotifications(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	notifications, err := n.notificationService.FindAllNotifications(uint(userID), ctx.QueryBool("unread"))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    notifications,
+	})
+}
+
+func (n *NotificationController) MarkRead(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	notificationId := ctx.Params("id")
+	notificationIdUint64, err := strconv.ParseUint(notificationId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid notification Id"})
+	}
+
+	if err := n.notificationService.MarkRead(uint(userID), uint(notificationIdUint64)); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Notification marked as read"})
+}
+
+func (n *NotificationController) MarkAllRead(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	if err := n.notificationService.MarkAllRead(uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "All notifications marked as read"})
+}
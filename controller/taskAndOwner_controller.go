@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"manajemen_tugas_master/helper"
 	"manajemen_tugas_master/model/domain"
 	"manajemen_tugas_master/model/web"
 	"manajemen_tugas_master/service"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -29,32 +31,767 @@ func (t *TaskAndOwnerController) CreateTaskAndOwner(ctx *fiber.Ctx) error {
 	nameTask := ctx.FormValue("name_task")
 	task.NameTask = nameTask
 
-	taskDB, ownerDB, err := t.taskAndOwnerService.CreateTaskAndOwner(user, &task)
+	// workspace_id, boleh kosong jika user hanya memiliki satu workspace
+	workspaceId := ctx.FormValue("workspace_id")
+	if workspaceId != "" {
+		workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+		}
+		task.WorkspaceID = workspaceIdUint64
+	}
+
+	// template_id opsional, task akan diisi dengan isian template
+	var templateID uint64
+	if templateId := ctx.Query("template_id"); templateId != "" {
+		templateIdUint64, err := strconv.ParseUint(templateId, 10, 64)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid template Id"})
+		}
+		templateID = templateIdUint64
+	}
+
+	taskDB, ownerDB, err := t.taskAndOwnerService.CreateTaskAndOwner(user, &task, uint(templateID))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	type CreateResponse struct {
+		TaskID      uint64 `json:"task_id"`
+		WorkspaceID uint64 `json:"workspace_id"`
+		NameTask    string `json:"name_task"`
+		OwnerID     uint64 `json:"owner_id"`
+		UserEmail   string `json:"user_email"`
+		UserID      uint64 `json:"user_id"`
+	}
+
+	response := web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data: CreateResponse{
+			TaskID:      taskDB.ID,
+			WorkspaceID: taskDB.WorkspaceID,
+			NameTask:    taskDB.NameTask,
+			OwnerID:     ownerDB.ID,
+			UserEmail:   ownerDB.Email,
+			UserID:      ownerDB.UserID,
+		},
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (t *TaskAndOwnerController) CreateSubtask(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user").(*domain.User)
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	var task domain.Task
+	task.NameTask = ctx.FormValue("name_task")
+	task.Priority = ctx.FormValue("priority")
+
+	if planningDueDate := ctx.FormValue("planning_due_date"); planningDueDate != "" {
+		dueDate, err := helper.ParseDueDate(planningDueDate)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		dueDate = dueDate.UTC()
+		task.PlanningDueDate = &dueDate
+	}
+
+	if projectDueDate := ctx.FormValue("project_due_date"); projectDueDate != "" {
+		dueDate, err := helper.ParseDueDate(projectDueDate)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		dueDate = dueDate.UTC()
+		task.ProjectDueDate = &dueDate
+	}
+
+	taskDB, _, err := t.taskAndOwnerService.CreateSubtask(user, uint(taskIdUint64), &task)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response := web.CreateResponseTask(taskDB)
+	return ctx.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (t *TaskAndOwnerController) GetSubtasks(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	subtasks, err := t.taskAndOwnerService.FindSubtasks(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseTaskList(subtasks))
+}
+
+// errorStatus mengembalikan status code dari error workflow, error lainnya memakai status fallback
+func errorStatus(err error, fallback int) int {
+	var workflowErr *service.WorkflowError
+	if errors.As(err, &workflowErr) {
+		return workflowErr.Status
+	}
+	return fallback
+}
+
+// parseOrderedIDs membaca daftar id yang dipisahkan koma
+func parseOrderedIDs(value string) ([]uint, error) {
+	var ids []uint
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		idUint64, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(idUint64))
+	}
+	return ids, nil
+}
+
+func (t *TaskAndOwnerController) ReorderSubtasks(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	// subtask_ids berisi id subtask sesuai urutan baru, dipisahkan koma, contoh: 3,1,2
+	subtaskIDs, err := parseOrderedIDs(ctx.FormValue("subtask_ids"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subtask Id"})
+	}
+	if len(subtaskIDs) == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "subtask_ids is required"})
+	}
+
+	if err := t.taskAndOwnerService.ReorderSubtasks(uint(taskIdUint64), subtaskIDs, uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Subtasks reordered successfully"})
+}
+
+func (t *TaskAndOwnerController) GetDependencies(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	blockedBy, blocks, err := t.taskAndOwnerService.FindDependencies(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	type CreateResponse struct {
+		BlockedBy []*domain.TaskDependency `json:"blocked_by"`
+		Blocks    []*domain.TaskDependency `json:"blocks"`
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data: CreateResponse{
+			BlockedBy: blockedBy,
+			Blocks:    blocks,
+		},
+	})
+}
+
+func (t *TaskAndOwnerController) AddDependency(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	blockedById := ctx.FormValue("blocked_by_id")
+	blockedByIdUint64, err := strconv.ParseUint(blockedById, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid blocked_by_id"})
+	}
+
+	dependency, err := t.taskAndOwnerService.AddDependency(uint(taskIdUint64), uint(blockedByIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    dependency,
+	})
+}
+
+func (t *TaskAndOwnerController) DeleteDependency(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	blockedById := ctx.Params("blocked_by_id")
+	blockedByIdUint64, err := strconv.ParseUint(blockedById, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid blocked_by_id"})
+	}
+
+	if err := t.taskAndOwnerService.DeleteDependency(uint(taskIdUint64), uint(blockedByIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Dependency deleted successfully"})
+}
+
+func (t *TaskAndOwnerController) AttachLabel(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	labelId := ctx.FormValue("label_id")
+	labelIdUint64, err := strconv.ParseUint(labelId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid label Id"})
+	}
+
+	label, err := t.taskAndOwnerService.AttachLabel(uint(taskIdUint64), uint(labelIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    label,
+	})
+}
+
+func (t *TaskAndOwnerController) DetachLabel(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	labelId := ctx.Params("label_id")
+	labelIdUint64, err := strconv.ParseUint(labelId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid label Id"})
+	}
+
+	if err := t.taskAndOwnerService.DetachLabel(uint(taskIdUint64), uint(labelIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Label detached successfully"})
+}
+
+func (t *TaskAndOwnerController) SetCustomFieldValue(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	fieldId := ctx.Params("field_id")
+	fieldIdUint64, err := strconv.ParseUint(fieldId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid custom field Id"})
+	}
+
+	value, err := t.taskAndOwnerService.SetCustomFieldValue(uint(taskIdUint64), uint(fieldIdUint64), ctx.FormValue("value"), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    value,
+	})
+}
+
+func (t *TaskAndOwnerController) DeleteCustomFieldValue(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	fieldId := ctx.Params("field_id")
+	fieldIdUint64, err := strconv.ParseUint(fieldId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid custom field Id"})
+	}
+
+	if err := t.taskAndOwnerService.DeleteCustomFieldValue(uint(taskIdUint64), uint(fieldIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Custom field value deleted successfully"})
+}
+
+// parseTaskFilter membaca query pagination, filter dan sorting, filter custom field dibaca dari query cf_<id>=<value>
+func parseTaskFilter(ctx *fiber.Ctx) (*domain.TaskFilter, error) {
+	var filter domain.TaskFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return nil, err
+	}
+
+	for key, value := range ctx.Queries() {
+		if !strings.HasPrefix(key, "cf_") {
+			continue
+		}
+		fieldID, err := strconv.ParseUint(strings.TrimPrefix(key, "cf_"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if filter.CustomFields == nil {
+			filter.CustomFields = map[uint64]string{}
+		}
+		filter.CustomFields[fieldID] = value
+	}
+
+	return &filter, nil
+}
+
+func (t *TaskAndOwnerController) CreateChecklistItem(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	var item domain.ChecklistItem
+	item.Title = ctx.FormValue("title")
+
+	itemDB, err := t.taskAndOwnerService.CreateChecklistItem(uint(taskIdUint64), &item, ctx.FormValue("assignee"), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    itemDB,
+	})
+}
+
+func (t *TaskAndOwnerController) GetChecklist(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	items, err := t.taskAndOwnerService.FindChecklist(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    items,
+	})
+}
+
+func (t *TaskAndOwnerController) UpdateChecklistItem(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	itemId := ctx.Params("item_id")
+	itemIdUint64, err := strconv.ParseUint(itemId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid checklist item Id"})
+	}
+
+	// field yang tidak dikirim tidak diubah, unassign=true menghapus assignee
+	var (
+		title    *string
+		done     *bool
+		assignee *string
+	)
+	if value := ctx.FormValue("title"); value != "" {
+		title = &value
+	}
+	if value := ctx.FormValue("done"); value != "" {
+		doneBool, err := strconv.ParseBool(value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "done must be true or false"})
+		}
+		done = &doneBool
+	}
+	if value := ctx.FormValue("assignee"); value != "" {
+		assignee = &value
+	}
+	if ctx.FormValue("unassign") == "true" {
+		value := ""
+		assignee = &value
+	}
+
+	item, err := t.taskAndOwnerService.UpdateChecklistItem(uint(taskIdUint64), uint(itemIdUint64), title, done, assignee, uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    item,
+	})
+}
+
+func (t *TaskAndOwnerController) ReorderChecklist(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	// item_ids berisi id checklist item sesuai urutan baru, dipisahkan koma, contoh: 3,1,2
+	itemIDs, err := parseOrderedIDs(ctx.FormValue("item_ids"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid checklist item Id"})
+	}
+	if len(itemIDs) == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "item_ids is required"})
+	}
+
+	if err := t.taskAndOwnerService.ReorderChecklist(uint(taskIdUint64), itemIDs, uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Checklist reordered successfully"})
+}
+
+func (t *TaskAndOwnerController) DeleteChecklistItem(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	itemId := ctx.Params("item_id")
+	itemIdUint64, err := strconv.ParseUint(itemId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid checklist item Id"})
+	}
+
+	if err := t.taskAndOwnerService.DeleteChecklistItem(uint(taskIdUint64), uint(itemIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Checklist item deleted successfully"})
+}
+
+func (t *TaskAndOwnerController) SetRecurrence(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	var recurrence domain.TaskRecurrence
+	recurrence.Frequency = ctx.FormValue("frequency")
+
+	if interval := ctx.FormValue("interval"); interval != "" {
+		recurrence.Interval, err = strconv.Atoi(interval)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid interval"})
+		}
+	}
+
+	if count := ctx.FormValue("count"); count != "" {
+		countInt, err := strconv.Atoi(count)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid count"})
+		}
+		recurrence.Count = &countInt
+	}
+
+	if startAt := ctx.FormValue("start_at"); startAt != "" {
+		date, err := helper.ParseDueDate(startAt)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		recurrence.StartAt = date.UTC()
+	}
+
+	if until := ctx.FormValue("until"); until != "" {
+		date, err := helper.ParseDueDate(until)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		date = date.UTC()
+		recurrence.Until = &date
+	}
+
+	recurrenceDB, err := t.taskAndOwnerService.SetRecurrence(uint(taskIdUint64), &recurrence, uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    recurrenceDB,
+	})
+}
+
+func (t *TaskAndOwnerController) DeleteRecurrence(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	if err := t.taskAndOwnerService.DeleteRecurrence(uint(taskIdUint64), uint(userID)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Recurrence deleted successfully"})
+}
+
+func (t *TaskAndOwnerController) SubmitPlanning(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	approval, err := t.taskAndOwnerService.SubmitPlanning(uint(taskIdUint64), ctx.FormValue("note"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    approval,
+	})
+}
+
+func (t *TaskAndOwnerController) ApprovePlanning(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	approval, err := t.taskAndOwnerService.ApprovePlanning(uint(taskIdUint64), ctx.FormValue("note"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    approval,
+	})
+}
+
+func (t *TaskAndOwnerController) RejectPlanning(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	approval, err := t.taskAndOwnerService.RejectPlanning(uint(taskIdUint64), ctx.FormValue("reason"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    approval,
+	})
+}
+
+func (t *TaskAndOwnerController) GetPlanningApprovals(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	approvals, err := t.taskAndOwnerService.FindPlanningApprovals(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    approvals,
+	})
+}
+
+func (t *TaskAndOwnerController) SubmitProjectReview(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	review, err := t.taskAndOwnerService.SubmitProjectReview(uint(taskIdUint64), ctx.FormValue("note"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    review,
+	})
+}
+
+func (t *TaskAndOwnerController) AcceptProjectReview(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	review, err := t.taskAndOwnerService.AcceptProjectReview(uint(taskIdUint64), ctx.FormValue("comment"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    review,
+	})
+}
+
+func (t *TaskAndOwnerController) RequestProjectChanges(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	type CreateResponse struct {
-		TaskID    uint64 `json:"task_id"`
-		NameTask  string `json:"name_task"`
-		OwnerID   uint64 `json:"owner_id"`
-		UserEmail string `json:"user_email"`
-		UserID    uint64 `json:"user_id"`
+	review, err := t.taskAndOwnerService.RequestProjectChanges(uint(taskIdUint64), ctx.FormValue("comment"), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	response := web.WebResponse{
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
 		Code:    200,
 		Message: "Success",
-		Data: CreateResponse{
-			TaskID:    taskDB.ID,
-			NameTask:  taskDB.NameTask,
-			OwnerID:   ownerDB.ID,
-			UserEmail: ownerDB.Email,
-			UserID:    ownerDB.UserID,
-		},
+		Data:    review,
+	})
+}
+
+func (t *TaskAndOwnerController) GetProjectReviews(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(response)
+	reviews, err := t.taskAndOwnerService.FindProjectReviews(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    reviews,
+	})
+}
+
+func (t *TaskAndOwnerController) GetTaskActivity(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	var filter domain.ActivityFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid query parameter"})
+	}
+
+	activities, total, err := t.taskAndOwnerService.FindTaskActivity(uint(taskIdUint64), uint(userID), &filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseActivityPage(activities, &filter, total))
 }
 
 func (t *TaskAndOwnerController) GetTaskAndOwnerById(ctx *fiber.Ctx) error {
@@ -64,7 +801,9 @@ func (t *TaskAndOwnerController) GetTaskAndOwnerById(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	task, err := t.taskAndOwnerService.GetTaskAndOwnerById(uint(taskIdUint64))
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	task, err := t.taskAndOwnerService.GetTaskAndOwnerById(uint(taskIdUint64), uint(userID))
 	if err != nil {
 		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 	}
@@ -73,48 +812,57 @@ func (t *TaskAndOwnerController) GetTaskAndOwnerById(ctx *fiber.Ctx) error {
 }
 
 func (t *TaskAndOwnerController) GetAllTasksAndOwners(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllTasksAndOwners()
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	filter, err := parseTaskFilter(ctx)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid query parameter"})
+	}
+
+	tasks, total, err := t.taskAndOwnerService.FindAllTasksAndOwners(uint(userID), filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseTasks(tasks))
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseTasksPage(tasks, filter, total))
 }
 
-func (t *TaskAndOwnerController) GetAllOwners(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllOwners()
+func (t *TaskAndOwnerController) GetMyTasks(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	filter, err := parseTaskFilter(ctx)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid query parameter"})
 	}
 
-	type CreateResponse struct {
-		TaskID    uint64 `json:"task_id"`
-		NameTask  string `json:"name_task"`
-		OwnerID   uint64 `json:"owner_id"`
-		UserEmail string `json:"user_email"`
-		UserID    uint64 `json:"user_id"`
+	tasks, total, err := t.taskAndOwnerService.FindMyTasks(uint(userID), filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var response []web.WebResponse
-	for _, task := range tasks {
-		response = append(response, web.WebResponse{
-			Code:    200,
-			Message: "Success",
-			Data: CreateResponse{
-				TaskID:    task.ID,
-				NameTask:  task.NameTask,
-				OwnerID:   task.Owner.ID,
-				UserEmail: task.Owner.Email,
-				UserID:    task.Owner.UserID,
-			},
-		})
-	}
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseMyTasks(tasks, userID, filter, total))
+}
 
-	return ctx.Status(fiber.StatusOK).JSON(response)
+func (t *TaskAndOwnerController) GetAllOwners(ctx *fiber.Ctx) error {
+	return t.getAllMembers(ctx, domain.TaskRoleOwner)
 }
 
 func (t *TaskAndOwnerController) GetAllManagers(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllManagers()
+	return t.getAllMembers(ctx, domain.TaskRoleManager)
+}
+
+func (t *TaskAndOwnerController) GetAllEmployees(ctx *fiber.Ctx) error {
+	return t.getAllMembers(ctx, domain.TaskRoleEmployee)
+}
+
+func (t *TaskAndOwnerController) GetAllMembers(ctx *fiber.Ctx) error {
+	return t.getAllMembers(ctx, ctx.Query("role"))
+}
+
+func (t *TaskAndOwnerController) getAllMembers(ctx *fiber.Ctx, role string) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	tasks, err := t.taskAndOwnerService.FindAllMembers(uint(userID), role)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -122,57 +870,25 @@ func (t *TaskAndOwnerController) GetAllManagers(ctx *fiber.Ctx) error {
 	type CreateResponse struct {
 		TaskID    uint64 `json:"task_id"`
 		NameTask  string `json:"name_task"`
-		ManagerID uint64 `json:"owner_id"`
+		MemberID  uint64 `json:"member_id"`
+		Role      string `json:"role"`
 		UserEmail string `json:"user_email"`
 		UserID    uint64 `json:"user_id"`
 	}
 
 	var response []web.WebResponse
 	for _, task := range tasks {
-		for _, manager := range task.Manager {
+		for _, member := range task.Members {
 			response = append(response, web.WebResponse{
 				Code:    200,
 				Message: "Success",
 				Data: CreateResponse{
 					TaskID:    task.ID,
 					NameTask:  task.NameTask,
-					ManagerID: manager.ID,
-					UserEmail: manager.Email,
-					UserID:    manager.UserID,
-				},
-			})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(response)
-}
-
-func (t *TaskAndOwnerController) GetAllEmployees(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllEmployees()
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	type CreateResponse struct {
-		TaskID     uint64 `json:"task_id"`
-		NameTask   string `json:"name_task"`
-		EmployeeID uint64 `json:"owner_id"`
-		UserEmail  string `json:"user_email"`
-		UserID     uint64 `json:"user_id"`
-	}
-
-	var response []web.WebResponse
-	for _, task := range tasks {
-		for _, employee := range task.Employee {
-			response = append(response, web.WebResponse{
-				Code:    200,
-				Message: "Success",
-				Data: CreateResponse{
-					TaskID:     task.ID,
-					NameTask:   task.NameTask,
-					EmployeeID: employee.ID,
-					UserEmail:  employee.Email,
-					UserID:     employee.UserID,
+					MemberID:  member.ID,
+					Role:      member.Role,
+					UserEmail: member.Email,
+					UserID:    member.UserID,
 				},
 			})
 		}
@@ -182,7 +898,9 @@ func (t *TaskAndOwnerController) GetAllEmployees(ctx *fiber.Ctx) error {
 }
 
 func (t *TaskAndOwnerController) GetAllPlanningFiles(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllPlanningFiles()
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	tasks, err := t.taskAndOwnerService.FindAllPlanningFiles(uint(userID))
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -216,7 +934,9 @@ func (t *TaskAndOwnerController) GetAllPlanningFiles(ctx *fiber.Ctx) error {
 }
 
 func (t *TaskAndOwnerController) GetAllProjectFiles(ctx *fiber.Ctx) error {
-	tasks, err := t.taskAndOwnerService.FindAllProjectFiles()
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	tasks, err := t.taskAndOwnerService.FindAllProjectFiles(uint(userID))
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -254,8 +974,7 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 		task         domain.Task
 		planningFile domain.PlanningFile
 		projectFile  domain.ProjectFile
-		manager      domain.Manager
-		employee     domain.Employee
+		members      []*domain.TaskMember
 	)
 
 	// user yang sedang login
@@ -275,10 +994,10 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 	//	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid form request"})
 	//}
 	if managerEmail != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
-		manager.Email = managerEmail
+		members = append(members, &domain.TaskMember{Email: managerEmail, Role: domain.TaskRoleManager})
 	}
 
 	// employee
@@ -287,17 +1006,17 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 	//	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid form request"})
 	//}
 	if employeeEmail != "" {
-		if err := t.taskAndOwnerService.UpdateValidationManager(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleManager); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
-		employee.Email = employeeEmail
+		members = append(members, &domain.TaskMember{Email: employeeEmail, Role: domain.TaskRoleEmployee})
 	}
 
 	// planning file
 	planningFiles, err := ctx.FormFile("planning_file")
 	// Validasi dengan UpdateTaskAndOwnerValidationForManager jika planning file diunggah terlebih dahulu
 	if planningFiles != nil {
-		if err := t.taskAndOwnerService.UpdateValidationManager(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleManager); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 		if err == nil {
@@ -317,7 +1036,7 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 	projectFiles, err := ctx.FormFile("project_file")
 	// Validasi dengan UpdateTaskAndOwnerValidationForEmployee jika project file diunggah terlebih dahulu
 	if projectFiles != nil {
-		if err := t.taskAndOwnerService.UpdateValidationEmployee(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleEmployee); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 		if err == nil {
@@ -336,7 +1055,7 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 	// field yang tidak berelasi pada task
 	nameTask := ctx.FormValue("name_task")
 	if nameTask != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 		task.NameTask = nameTask
@@ -344,106 +1063,177 @@ func (t *TaskAndOwnerController) UpdateTaskAndOwner(ctx *fiber.Ctx) error {
 
 	planningDescription := ctx.FormValue("planning_description")
 	if planningDescription != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 		task.PlanningDescription = planningDescription
 	}
 
-	planningStatus := ctx.FormValue("planning_status")
-	if planningStatus != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-		task.PlanningStatus = planningStatus
-
-	}
-
-	projectStatus := ctx.FormValue("project_status")
-	if projectStatus != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-		task.ProjectStatus = projectStatus
-	}
+	// role yang boleh mengubah status ditentukan oleh workflow pada service
+	task.PlanningStatus = ctx.FormValue("planning_status")
+	task.ProjectStatus = ctx.FormValue("project_status")
 
 	planningDueDate := ctx.FormValue("planning_due_date")
 	if planningDueDate != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		dueDate, err := helper.ParseDueDate(planningDueDate)
+		if err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
-		task.PlanningDueDate = planningDueDate
+		dueDate = dueDate.UTC()
+		task.PlanningDueDate = &dueDate
 	}
 
 	projectDueDate := ctx.FormValue("project_due_date")
 	if projectDueDate != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
-		task.ProjectDueDate = projectDueDate
+		dueDate, err := helper.ParseDueDate(projectDueDate)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		dueDate = dueDate.UTC()
+		task.ProjectDueDate = &dueDate
 	}
 
 	priority := ctx.FormValue("priority")
 	if priority != "" {
-		if err := t.taskAndOwnerService.UpdateValidationManager(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleManager); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 		task.Priority = priority
 	}
 
-	projectComment := ctx.FormValue("project_comment")
-	if projectComment != "" {
-		if err := t.taskAndOwnerService.UpdateValidationEmployee(uint(taskIdUint64), uint(userID)); err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-		task.ProjectComment = projectComment
+	// save
+	response, err := t.taskAndOwnerService.UpdateTaskAndOwner(&task, members, &planningFile, &projectFile, uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// save
-	response, err := t.taskAndOwnerService.UpdateTaskAndOwner(&task, &manager, &employee, &planningFile, &projectFile, uint(taskIdUint64))
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    response,
+	})
+}
+
+func (t *TaskAndOwnerController) GetAllInvitations(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user")
+	userId := user.(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
 	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	// undangan hanya bisa dilihat oleh owner dan manager, karena hanya mereka yang bisa mengundang
+	if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userId), domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	invitations, err := t.taskAndOwnerService.FindAllInvitations(uint(taskIdUint64))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
 		Code:    200,
 		Message: "Success",
-		Data:    response,
+		Data:    invitations,
 	})
 }
 
-func (t *TaskAndOwnerController) DeleteManager(ctx *fiber.Ctx) error {
+func (t *TaskAndOwnerController) DeleteInvitation(ctx *fiber.Ctx) error {
 	user := ctx.Locals("user")
 	userId := user.(*domain.User).ID
 
 	taskId := ctx.Params("id")
 	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid file Id"})
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	invitationId := ctx.Params("invitation_id")
+	invitationIdUint64, err := strconv.ParseUint(invitationId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid invitation Id"})
+	}
+
+	if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userId), domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := t.taskAndOwnerService.DeleteInvitation(uint(taskIdUint64), uint(invitationIdUint64), uint(userId)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Invitation deleted successfully"})
+}
+
+func (t *TaskAndOwnerController) GetAllTaskMembers(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	managerId := ctx.Params("manager_id")
-	managerIdUint64, err := strconv.ParseUint(managerId, 10, 64)
+	task, err := t.taskAndOwnerService.GetTaskAndOwnerById(uint(taskIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    task.Members,
+	})
+}
+
+func (t *TaskAndOwnerController) AddMember(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user")
+	userId := user.(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	if userId != 0 && taskId != "" {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userId)); err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-	} else {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "task dan manager id is required"})
+	email := ctx.FormValue("email")
+	role := ctx.FormValue("role")
+	if email == "" || role == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email and role is required"})
 	}
 
-	if err := t.taskAndOwnerService.DeleteManager(uint(taskIdUint64), uint(managerIdUint64)); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	// employee bisa ditambahkan oleh manager atau owner, role lainnya hanya oleh owner
+	allowedRoles := []string{domain.TaskRoleOwner}
+	if role == domain.TaskRoleEmployee {
+		allowedRoles = append(allowedRoles, domain.TaskRoleManager)
+	}
+	if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userId), allowedRoles...); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "File deleted successfully"})
+	members := []*domain.TaskMember{{Email: email, Role: role}}
+	response, err := t.taskAndOwnerService.UpdateTaskAndOwner(&domain.Task{}, members, &domain.PlanningFile{}, &domain.ProjectFile{}, uint(taskIdUint64), uint(userId))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    response,
+	})
 }
 
-func (t *TaskAndOwnerController) DeleteEmployee(ctx *fiber.Ctx) error {
+func (t *TaskAndOwnerController) UpdateMemberRole(ctx *fiber.Ctx) error {
 	user := ctx.Locals("user")
 	userId := user.(*domain.User).ID
 
@@ -453,25 +1243,86 @@ func (t *TaskAndOwnerController) DeleteEmployee(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
 	}
 
-	employeeId := ctx.Params("employee_id")
-	employeeIdUint64, err := strconv.ParseUint(employeeId, 10, 64)
+	memberId := ctx.Params("member_id")
+	memberIdUint64, err := strconv.ParseUint(memberId, 10, 64)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid file Id"})
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid member Id"})
 	}
 
-	if userId != 0 && taskId != "" {
-		if err := t.taskAndOwnerService.UpdateValidationManager(uint(taskIdUint64), uint(userId)); err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-	} else {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "task and employee id is required"})
+	role := ctx.FormValue("role")
+	if role == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role is required"})
 	}
 
-	if err := t.taskAndOwnerService.DeleteEmployee(uint(taskIdUint64), uint(employeeIdUint64)); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	member, err := t.taskAndOwnerService.UpdateMemberRole(uint(taskIdUint64), uint(memberIdUint64), role, uint(userId))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "File deleted successfully"})
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    member,
+	})
+}
+
+func (t *TaskAndOwnerController) TransferOwnership(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user")
+	userId := user.(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	email := ctx.FormValue("email")
+	if email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+
+	oldOwner, newOwner, err := t.taskAndOwnerService.TransferOwnership(uint(taskIdUint64), email, uint(userId))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	type CreateResponse struct {
+		Owner         *domain.TaskMember `json:"owner"`
+		PreviousOwner *domain.TaskMember `json:"previous_owner"`
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data: CreateResponse{
+			Owner:         newOwner,
+			PreviousOwner: oldOwner,
+		},
+	})
+}
+
+func (t *TaskAndOwnerController) DeleteMember(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user")
+	userId := user.(*domain.User).ID
+
+	taskId := ctx.Params("id")
+	taskIdUint64, err := strconv.ParseUint(taskId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task Id"})
+	}
+
+	memberId := ctx.Params("member_id")
+	memberIdUint64, err := strconv.ParseUint(memberId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid member Id"})
+	}
+
+	// validasi role dilakukan di service berdasarkan role member yang akan dihapus
+	if err := t.taskAndOwnerService.DeleteMember(uint(taskIdUint64), uint(memberIdUint64), uint(userId)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Member deleted successfully"})
 }
 
 func (t *TaskAndOwnerController) DeletePlanningFile(ctx *fiber.Ctx) error {
@@ -491,14 +1342,14 @@ func (t *TaskAndOwnerController) DeletePlanningFile(ctx *fiber.Ctx) error {
 	}
 
 	if userId != 0 && taskId != "" {
-		if err := t.taskAndOwnerService.UpdateValidationManager(uint(taskIdUint64), uint(userId)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userId), domain.TaskRoleManager); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 	} else {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "task and file id is required"})
 	}
 
-	fileName, err := t.taskAndOwnerService.DeletePlanningFile(uint(fileIdUint64))
+	fileName, err := t.taskAndOwnerService.DeletePlanningFile(uint(taskIdUint64), uint(fileIdUint64), uint(userId))
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -536,16 +1387,16 @@ func (t *TaskAndOwnerController) DeleteProjectFile(ctx *fiber.Ctx) error {
 	}
 
 	if userId != 0 && taskId != "" {
-		if err := t.taskAndOwnerService.UpdateValidationEmployee(uint(taskIdUint64), uint(userId)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userId), domain.TaskRoleEmployee); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 	} else {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "task and file id is required"})
 	}
 
-	fileName, err := t.taskAndOwnerService.DeleteProjectFile(uint(fileIdUint64))
+	fileName, err := t.taskAndOwnerService.DeleteProjectFile(uint(taskIdUint64), uint(fileIdUint64), uint(userId))
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	// aws s3
@@ -575,14 +1426,14 @@ func (t *TaskAndOwnerController) DeleteTaskAndOwner(ctx *fiber.Ctx) error {
 	userID := user.(*domain.User).ID
 
 	if taskId != "" && userID != 0 {
-		if err := t.taskAndOwnerService.UpdateValidationOwner(uint(taskIdUint64), uint(userID)); err != nil {
+		if err := t.taskAndOwnerService.UpdateValidationRole(uint(taskIdUint64), uint(userID), domain.TaskRoleOwner); err != nil {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 	} else {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "task and file id is required"})
 	}
 
-	if err := t.taskAndOwnerService.DeleteTaskAndOwner(uint(taskIdUint64)); err != nil {
+	if err := t.taskAndOwnerService.DeleteTaskAndOwner(uint(taskIdUint64), uint(userID)); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
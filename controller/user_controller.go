@@ -6,7 +6,6 @@ import (
 	"manajemen_tugas_master/model/web"
 	"manajemen_tugas_master/service"
 	"strconv"
-	"time"
 )
 
 type UserController struct {
@@ -39,18 +38,106 @@ func (c *UserController) LoginUser(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	tokenString, err := c.userService.LoginUser(user)
+	tokens, err := c.userService.LoginUser(user)
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	setTokenCookies(ctx, tokens)
+
+	// token juga dikirim di body untuk client yang memakai header Authorization: Bearer
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Login successfully", "data": tokens})
+}
+
+func (c *UserController) RefreshToken(ctx *fiber.Ctx) error {
+	// refresh token bisa dikirim melalui cookie atau form value
+	refreshToken := ctx.Cookies("RefreshToken")
+	if refreshToken == "" {
+		refreshToken = ctx.FormValue("refresh_token")
+	}
+
+	tokens, err := c.userService.RefreshToken(refreshToken)
+	if err != nil {
+		clearTokenCookies(ctx)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	setTokenCookies(ctx, tokens)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Token refreshed successfully", "data": tokens})
+}
+
+func (c *UserController) LogoutUser(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user").(*domain.User)
+	accessToken := ctx.Locals("token").(string)
+
+	refreshToken := ctx.Cookies("RefreshToken")
+	if refreshToken == "" {
+		refreshToken = ctx.FormValue("refresh_token")
+	}
+
+	// ?all=true untuk logout dari semua perangkat
+	allSessions := ctx.QueryBool("all", false)
+
+	if err := c.userService.LogoutUser(user, accessToken, refreshToken, allSessions); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	clearTokenCookies(ctx)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logout successfully"})
+}
+
+func (c *UserController) UpdateUserRole(ctx *fiber.Ctx) error {
+	actor := ctx.Locals("user").(*domain.User)
+
+	userId := ctx.Params("id")
+	userIdUint64, err := strconv.ParseUint(userId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user Id"})
+	}
+
+	var request struct {
+		Role string `json:"role" form:"role"`
+	}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	user, err := c.userService.UpdateUserRole(actor, uint(userIdUint64), request.Role)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseUser(user))
+}
+
+// canManageUser mengecek apakah user yang login adalah pemilik akun atau admin
+func canManageUser(ctx *fiber.Ctx, userID uint64) bool {
+	user, ok := ctx.Locals("user").(*domain.User)
+	if !ok {
+		return false
+	}
+	return user.ID == userID || user.IsAdmin()
+}
+
+func setTokenCookies(ctx *fiber.Ctx, tokens *web.TokenResponse) {
 	ctx.Cookie(&fiber.Cookie{
-		Name:    "Authorization",
-		Value:   tokenString,
-		Expires: time.Now().Add(time.Hour * 24 * 1),
+		Name:     "Authorization",
+		Value:    tokens.AccessToken,
+		Expires:  tokens.AccessTokenExpiresAt,
+		HTTPOnly: true,
 	})
+	ctx.Cookie(&fiber.Cookie{
+		Name:     "RefreshToken",
+		Value:    tokens.RefreshToken,
+		Expires:  tokens.RefreshTokenExpiresAt,
+		HTTPOnly: true,
+	})
+}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Login successfully"})
+func clearTokenCookies(ctx *fiber.Ctx) {
+	ctx.ClearCookie("Authorization", "RefreshToken")
 }
 
 func (c *UserController) GetUserByID(ctx *fiber.Ctx) error {
@@ -91,6 +178,11 @@ func (c *UserController) UpdateUser(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user Id"})
 	}
 
+	// user hanya boleh mengubah datanya sendiri, kecuali admin
+	if !canManageUser(ctx, userIdUint64) {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only update your own account"})
+	}
+
 	_, err = c.userService.GetUserByID(uint(userIdUint64))
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "User not found"})
@@ -118,6 +210,11 @@ func (c *UserController) DeleteUser(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user Id"})
 	}
 
+	// user hanya boleh menghapus akunnya sendiri, kecuali admin
+	if !canManageUser(ctx, userIdUint64) {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only delete your own account"})
+	}
+
 	// Cek apakah user dengan Id tersebut ada
 	_, err = c.userService.GetUserByID(uint(userIdUint64))
 	if err != nil {
@@ -130,3 +227,62 @@ func (c *UserController) DeleteUser(ctx *fiber.Ctx) error {
 	}
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Deleted successfully"})
 }
+
+func (c *UserController) ForgotPassword(ctx *fiber.Ctx) error {
+	var request struct {
+		Email string `json:"email" form:"email"`
+	}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := c.userService.ForgotPassword(request.Email); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+func (c *UserController) ResetPassword(ctx *fiber.Ctx) error {
+	var request struct {
+		Token    string `json:"token" form:"token"`
+		Password string `json:"password" form:"password"`
+	}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	// token juga bisa diambil dari query string link email
+	if request.Token == "" {
+		request.Token = ctx.Query("token")
+	}
+
+	if err := c.userService.ResetPassword(request.Token, request.Password); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password has been reset successfully, Please login again"})
+}
+
+func (c *UserController) VerifyEmail(ctx *fiber.Ctx) error {
+	user, err := c.userService.VerifyEmail(ctx.Query("token"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseUser(user))
+}
+
+func (c *UserController) ResendVerification(ctx *fiber.Ctx) error {
+	var request struct {
+		Email string `json:"email" form:"email"`
+	}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := c.userService.ResendVerification(request.Email); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "If the email is registered and not verified yet, a verification link has been sent"})
+}
@@ -0,0 +1,527 @@
+package controller
+
+import (
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/model/web"
+	"manajemen_tugas_master/service"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WorkspaceController struct {
+	workspaceService service.WorkspaceService
+}
+
+func NewWorkspaceController(workspaceService service.WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{workspaceService}
+}
+
+func (w *WorkspaceController) CreateWorkspace(ctx *fiber.Ctx) error {
+	user := ctx.Locals("user").(*domain.User)
+
+	var workspace domain.Workspace
+	workspace.Name = ctx.FormValue("name")
+
+	workspaceDB, err := w.workspaceService.CreateWorkspace(user, &workspace)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.CreateResponseWorkspace(workspaceDB))
+}
+
+func (w *WorkspaceController) GetWorkspaceById(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	workspace, err := w.workspaceService.GetWorkspaceById(uint(workspaceIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Workspace not found"})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseWorkspace(workspace))
+}
+
+func (w *WorkspaceController) GetAllWorkspaces(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaces, err := w.workspaceService.FindAllWorkspaces(uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response := make([]web.WebResponse, len(workspaces))
+	for i, workspace := range workspaces {
+		response[i] = web.CreateResponseWorkspace(workspace)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (w *WorkspaceController) UpdateWorkspace(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	var workspace domain.Workspace
+	workspace.ID = workspaceIdUint64
+	workspace.Name = ctx.FormValue("name")
+
+	workspaceDB, err := w.workspaceService.UpdateWorkspace(&workspace, uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseWorkspace(workspaceDB))
+}
+
+func (w *WorkspaceController) AddMember(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	member, err := w.workspaceService.AddMember(uint(workspaceIdUint64), uint(userID), ctx.FormValue("email"), ctx.FormValue("role"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    member,
+	})
+}
+
+func (w *WorkspaceController) DeleteMember(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	memberUserId := ctx.Params("user_id")
+	memberUserIdUint64, err := strconv.ParseUint(memberUserId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user Id"})
+	}
+
+	if err := w.workspaceService.DeleteMember(uint(workspaceIdUint64), uint(userID), uint(memberUserIdUint64)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Member deleted successfully"})
+}
+
+func (w *WorkspaceController) CreateLabel(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	var label domain.Label
+	label.Name = ctx.FormValue("name")
+	label.Color = ctx.FormValue("color")
+
+	labelDB, err := w.workspaceService.CreateLabel(uint(workspaceIdUint64), uint(userID), &label)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    labelDB,
+	})
+}
+
+func (w *WorkspaceController) GetAllLabels(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	labels, err := w.workspaceService.FindAllLabels(uint(workspaceIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    labels,
+	})
+}
+
+func (w *WorkspaceController) UpdateLabel(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	labelId := ctx.Params("label_id")
+	labelIdUint64, err := strconv.ParseUint(labelId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid label Id"})
+	}
+
+	var label domain.Label
+	label.ID = labelIdUint64
+	label.Name = ctx.FormValue("name")
+	label.Color = ctx.FormValue("color")
+
+	labelDB, err := w.workspaceService.UpdateLabel(uint(workspaceIdUint64), uint(userID), &label)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    labelDB,
+	})
+}
+
+func (w *WorkspaceController) DeleteLabel(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	labelId := ctx.Params("label_id")
+	labelIdUint64, err := strconv.ParseUint(labelId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid label Id"})
+	}
+
+	if err := w.workspaceService.DeleteLabel(uint(workspaceIdUint64), uint(userID), uint(labelIdUint64)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Label deleted successfully"})
+}
+
+// customFieldOptions membaca pilihan custom field select dari form "options" yang dipisahkan koma
+func customFieldOptions(ctx *fiber.Ctx) []string {
+	options := ctx.FormValue("options")
+	if options == "" {
+		return nil
+	}
+	return strings.Split(options, ",")
+}
+
+func (w *WorkspaceController) CreateCustomField(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	var field domain.CustomField
+	field.Name = ctx.FormValue("name")
+	field.Type = ctx.FormValue("type")
+	field.Options = customFieldOptions(ctx)
+
+	fieldDB, err := w.workspaceService.CreateCustomField(uint(workspaceIdUint64), uint(userID), &field)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    fieldDB,
+	})
+}
+
+func (w *WorkspaceController) GetAllCustomFields(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	fields, err := w.workspaceService.FindAllCustomFields(uint(workspaceIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    fields,
+	})
+}
+
+func (w *WorkspaceController) UpdateCustomField(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	fieldId := ctx.Params("field_id")
+	fieldIdUint64, err := strconv.ParseUint(fieldId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid custom field Id"})
+	}
+
+	var field domain.CustomField
+	field.ID = fieldIdUint64
+	field.Name = ctx.FormValue("name")
+	field.Options = customFieldOptions(ctx)
+
+	fieldDB, err := w.workspaceService.UpdateCustomField(uint(workspaceIdUint64), uint(userID), &field)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    fieldDB,
+	})
+}
+
+func (w *WorkspaceController) DeleteCustomField(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	fieldId := ctx.Params("field_id")
+	fieldIdUint64, err := strconv.ParseUint(fieldId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid custom field Id"})
+	}
+
+	if err := w.workspaceService.DeleteCustomField(uint(workspaceIdUint64), uint(userID), uint(fieldIdUint64)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Custom field deleted successfully"})
+}
+
+// taskTemplateForm membaca isian template dari form, email dipisahkan koma dan item checklist dipisahkan baris baru
+func taskTemplateForm(ctx *fiber.Ctx) (*domain.TaskTemplate, error) {
+	var template domain.TaskTemplate
+	template.Name = ctx.FormValue("name")
+	template.NameTask = ctx.FormValue("name_task")
+	template.PlanningDescription = ctx.FormValue("planning_description")
+	template.Priority = ctx.FormValue("priority")
+
+	for form, values := range map[string]*[]string{"managers": &template.Managers, "employees": &template.Employees} {
+		for _, email := range strings.Split(ctx.FormValue(form), ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				*values = append(*values, email)
+			}
+		}
+	}
+	for _, title := range strings.Split(ctx.FormValue("checklist"), "\n") {
+		if title = strings.TrimSpace(title); title != "" {
+			template.Checklist = append(template.Checklist, title)
+		}
+	}
+
+	for form, offset := range map[string]**int{"planning_due_offset_days": &template.PlanningDueOffsetDays, "project_due_offset_days": &template.ProjectDueOffsetDays} {
+		value := ctx.FormValue(form)
+		if value == "" {
+			continue
+		}
+		days, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s", form)
+		}
+		*offset = &days
+	}
+
+	return &template, nil
+}
+
+func (w *WorkspaceController) CreateTaskTemplate(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	template, err := taskTemplateForm(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	templateDB, err := w.workspaceService.CreateTaskTemplate(uint(workspaceIdUint64), uint(userID), template)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    templateDB,
+	})
+}
+
+func (w *WorkspaceController) GetAllTaskTemplates(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	templates, err := w.workspaceService.FindAllTaskTemplates(uint(workspaceIdUint64), uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    templates,
+	})
+}
+
+func (w *WorkspaceController) GetTaskTemplate(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	templateId := ctx.Params("template_id")
+	templateIdUint64, err := strconv.ParseUint(templateId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid template Id"})
+	}
+
+	template, err := w.workspaceService.FindTaskTemplate(uint(workspaceIdUint64), uint(userID), uint(templateIdUint64))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    template,
+	})
+}
+
+func (w *WorkspaceController) UpdateTaskTemplate(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	templateId := ctx.Params("template_id")
+	templateIdUint64, err := strconv.ParseUint(templateId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid template Id"})
+	}
+
+	template, err := taskTemplateForm(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	template.ID = templateIdUint64
+
+	templateDB, err := w.workspaceService.UpdateTaskTemplate(uint(workspaceIdUint64), uint(userID), template)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    templateDB,
+	})
+}
+
+func (w *WorkspaceController) DeleteTaskTemplate(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	templateId := ctx.Params("template_id")
+	templateIdUint64, err := strconv.ParseUint(templateId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid template Id"})
+	}
+
+	if err := w.workspaceService.DeleteTaskTemplate(uint(workspaceIdUint64), uint(userID), uint(templateIdUint64)); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Template deleted successfully"})
+}
+
+func (w *WorkspaceController) GetActivity(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("user").(*domain.User).ID
+
+	workspaceId := ctx.Params("id")
+	workspaceIdUint64, err := strconv.ParseUint(workspaceId, 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace Id"})
+	}
+
+	var filter domain.ActivityFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid query parameter"})
+	}
+
+	activities, total, err := w.workspaceService.FindActivity(uint(workspaceIdUint64), uint(userID), &filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(web.CreateResponseActivityPage(activities, &filter, total))
+}
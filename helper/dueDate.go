@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// dueDateLayouts berisi format tanggal yang diterima untuk due date
+var dueDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDueDate mengubah string due date menjadi time.Time, tanggal tanpa zona waktu dianggap UTC
+func ParseDueDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dueDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("Invalid date format, use ISO-8601 (e.g. 2024-01-31 or 2024-01-31T17:00:00+07:00)")
+}
@@ -0,0 +1,44 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDueDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"rfc3339 utc", "2024-05-01T10:30:00Z", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false},
+		{"rfc3339 with offset", "2024-05-01T17:30:00+07:00", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false},
+		{"datetime without zone", "2024-05-01T10:30:00", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false},
+		{"datetime with space", "2024-05-01 10:30:00", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false},
+		{"date only", "2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"surrounding whitespace", "  2024-05-01  ", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"empty string", "", time.Time{}, true},
+		{"day first format", "01/05/2024", time.Time{}, true},
+		{"invalid date", "2024-02-30", time.Time{}, true},
+		{"free text", "besok", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDueDate(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got %v", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package helper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// mention email, contoh: @budi@gmail.com
+	mentionEmailRegex = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	// mention user id, contoh: @12
+	mentionUserIDRegex = regexp.MustCompile(`(?:^|[^\w@])@(\d+)\b`)
+)
+
+// ParseMentions mengambil daftar email dan user id yang di-mention pada teks, tanpa duplikat
+func ParseMentions(text string) ([]string, []uint64) {
+	var (
+		emails  []string
+		userIDs []uint64
+	)
+
+	seenEmails := map[string]bool{}
+	for _, match := range mentionEmailRegex.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if !seenEmails[email] {
+			seenEmails[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	seenUserIDs := map[uint64]bool{}
+	for _, match := range mentionUserIDRegex.FindAllStringSubmatch(text, -1) {
+		userID, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || seenUserIDs[userID] {
+			continue
+		}
+		seenUserIDs[userID] = true
+		userIDs = append(userIDs, userID)
+	}
+
+	return emails, userIDs
+}
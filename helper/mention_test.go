@@ -0,0 +1,38 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantEmails  []string
+		wantUserIDs []uint64
+	}{
+		{"no mention", "tolong cek laporan ini", nil, nil},
+		{"email mention", "halo @budi@gmail.com tolong dicek", []string{"budi@gmail.com"}, nil},
+		{"email mention is lowercased", "@Budi.Santoso@Gmail.COM", []string{"budi.santoso@gmail.com"}, nil},
+		{"email mention at end of sentence", "sudah dikirim ke @budi@gmail.com.", []string{"budi@gmail.com"}, nil},
+		{"user id mention", "@12 dan @7 silakan review", nil, []uint64{12, 7}},
+		{"user id mention in parentheses", "(cc @5)", nil, []uint64{5}},
+		{"duplicate mentions", "@3 @budi@gmail.com @3 @BUDI@gmail.com", []string{"budi@gmail.com"}, []uint64{3}},
+		{"mixed mentions", "@budi@gmail.com dan @4", []string{"budi@gmail.com"}, []uint64{4}},
+		{"plain email is not a mention", "kirim ke budi@gmail.com", nil, nil},
+		{"number inside word is not a mention", "versi@12 dan @12abc", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emails, userIDs := ParseMentions(tt.text)
+			if !reflect.DeepEqual(emails, tt.wantEmails) {
+				t.Fatalf("expected emails %v, got %v", tt.wantEmails, emails)
+			}
+			if !reflect.DeepEqual(userIDs, tt.wantUserIDs) {
+				t.Fatalf("expected user ids %v, got %v", tt.wantUserIDs, userIDs)
+			}
+		})
+	}
+}
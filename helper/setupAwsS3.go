@@ -70,37 +70,6 @@ func SetupS3Delete(filename string) error {
 	return nil
 }
 
-func SetupS3DeleteAll() error {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return fmt.Errorf("Error loading AWS config: %w", err)
-	}
-
-	// Buat koneksi ke AWS S3
-	svc := s3.NewFromConfig(cfg)
-
-	// Panggil ListObjectsV2 API untuk mendapatkan daftar objek dalam bucket
-	resp, err := svc.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
-		Bucket: aws.String("manajemen-tugas"),
-	})
-	if err != nil {
-		return fmt.Errorf("Error listing objects: %w", err)
-	}
-
-	// Iterasi setiap objek dan hapus
-	for _, obj := range resp.Contents {
-		_, err := svc.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-			Bucket: aws.String("manajemen-tugas"),
-			Key:    obj.Key,
-		})
-		if err != nil {
-			return fmt.Errorf("Error deleting object: %w", err)
-		}
-	}
-
-	return nil
-}
-
 //func SetupS3GetAllFiles() (map[string]string, map[string]string, error) {
 //	cfg, err := config.LoadDefaultConfig(context.TODO())
 //	if err != nil {
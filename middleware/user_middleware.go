@@ -1,31 +1,90 @@
 package middleware
 
 import (
-	"log"
+	"errors"
+	"manajemen_tugas_master/model/domain"
 	"manajemen_tugas_master/service"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func AuthUser(userService service.UserService) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		// Get the cookie from request
-		tokenString := ctx.Cookies("Authorization")
-		log.Println("tokenstring= " + tokenString)
+		// Ambil token dari header Authorization: Bearer, jika tidak ada gunakan cookie
+		tokenString, authType, err := extractToken(ctx)
+		if err != nil {
+			return unauthorized(ctx, authType, "invalid_request", err.Error())
+		}
 		if tokenString == "" {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Your session has expired, Please login again"})
+			return unauthorized(ctx, authType, "", "Authorization token is required, Please login first")
 		}
 
 		// Decode and validate the token
 		user, err := userService.RequireAuthUser(tokenString)
 		if err != nil {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Please log in to access this menu"})
+			switch {
+			case errors.Is(err, service.ErrTokenExpired):
+				return unauthorized(ctx, authType, "invalid_token", "Your session has expired, Please login again")
+			case errors.Is(err, service.ErrTokenRevoked):
+				return unauthorized(ctx, authType, "invalid_token", "Your session has been revoked, Please login again")
+			case errors.Is(err, service.ErrTokenMalformed):
+				return unauthorized(ctx, authType, "invalid_token", "Malformed authorization token")
+			default:
+				return unauthorized(ctx, authType, "invalid_token", "Please log in to access this menu")
+			}
 		}
 
 		// menyimpan data user agar bisa di akses jika diperlukan. Dan perlu di ingat data user akan berubah menjadi interface{}, bukan *domain.user lagi.
 		ctx.Locals("user", user)
+		// token disimpan agar bisa dicabut ketika logout
+		ctx.Locals("token", tokenString)
+		// jenis kredensial yang dipakai, "bearer" atau "cookie"
+		ctx.Locals("auth_type", authType)
 
 		// agar middleware terdapat pada route di bawahnya dan akan terus di eksekusi terlebih dahulu sebelum route di bawahnya.
 		return ctx.Next()
 	}
 }
+
+// AdminOnly harus dipasang setelah AuthUser, karena membutuhkan data user dari ctx.Locals("user")
+func AdminOnly() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user, ok := ctx.Locals("user").(*domain.User)
+		if !ok || !user.IsAdmin() {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only for admin"})
+		}
+
+		return ctx.Next()
+	}
+}
+
+// extractToken mengambil token dari header Authorization, atau dari cookie Authorization jika header kosong
+func extractToken(ctx *fiber.Ctx) (string, string, error) {
+	header := strings.TrimSpace(ctx.Get(fiber.HeaderAuthorization))
+	if header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", "bearer", errors.New("Authorization header must use the Bearer scheme")
+		}
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", "bearer", errors.New("Bearer token is empty")
+		}
+		return token, "bearer", nil
+	}
+
+	return ctx.Cookies("Authorization"), "cookie", nil
+}
+
+func unauthorized(ctx *fiber.Ctx, authType string, code string, message string) error {
+	// client yang memakai bearer token membutuhkan header WWW-Authenticate (RFC 6750)
+	if authType == "bearer" {
+		challenge := `Bearer realm="manajemen-tugas"`
+		if code != "" {
+			challenge += `, error="` + code + `"`
+		}
+		ctx.Set(fiber.HeaderWWWAuthenticate, challenge)
+	}
+	return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": message})
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// ChecklistItem adalah langkah kecil di dalam task yang bisa ditandai selesai
+type ChecklistItem struct {
+	ID         uint64     `json:"id" gorm:"primaryKey"`
+	TaskID     uint64     `json:"task_id" gorm:"index"`
+	Title      string     `json:"title" gorm:"size:255"`
+	Position   int        `json:"position"`
+	Done       bool       `json:"done" gorm:"default:false"`
+	DoneAt     *time.Time `json:"done_at"`
+	AssigneeID *uint64    `json:"assignee_id" gorm:"index"`
+	Assignee   *User      `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID;references:ID"`
+	CreatedAt  time.Time  `json:"-"`
+	UpdatedAt  time.Time  `json:"-"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Comment menggantikan field ProjectComment pada task, balasan disimpan sebagai comment dengan ParentID.
+// AuthorID kosong berarti user penulisnya sudah dihapus
+type Comment struct {
+	ID          uint64              `json:"id" gorm:"primaryKey"`
+	TaskID      uint64              `json:"task_id" gorm:"index"`
+	ParentID    *uint64             `json:"parent_id" gorm:"index"`
+	AuthorID    *uint64             `json:"author_id" gorm:"index"`
+	Author      *User               `json:"author,omitempty" gorm:"foreignKey:AuthorID;references:ID"`
+	Body        string              `json:"body" gorm:"type:text"`
+	Attachments []CommentAttachment `json:"attachments" gorm:"foreignKey:CommentID;references:ID"`
+	Replies     []Comment           `json:"replies,omitempty" gorm:"foreignKey:ParentID;references:ID"`
+	EditedAt    *time.Time          `json:"edited_at"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"-"`
+}
+
+// IsAuthor mengecek apakah comment ditulis oleh user tersebut
+func (c *Comment) IsAuthor(userID uint) bool {
+	return c.AuthorID != nil && *c.AuthorID == uint64(userID)
+}
+
+type CommentAttachment struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	CommentID uint64    `json:"comment_id" gorm:"index"`
+	FileUrl   string    `json:"file_url" gorm:"size:255"`
+	FileName  string    `json:"file_name" gorm:"size:255"`
+	CreatedAt time.Time `json:"-"`
+}
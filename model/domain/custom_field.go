@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	CustomFieldTypeText   = "text"
+	CustomFieldTypeNumber = "number"
+	CustomFieldTypeDate   = "date"
+	CustomFieldTypeSelect = "select"
+	CustomFieldTypeUser   = "user"
+)
+
+// CustomField adalah definisi field tambahan pada task yang dibuat oleh admin workspace
+type CustomField struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_field"`
+	Name        string    `json:"name" gorm:"size:100;uniqueIndex:idx_workspace_field"`
+	Type        string    `json:"type" gorm:"size:20"`
+	Options     []string  `json:"options,omitempty" gorm:"serializer:json;type:text"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+// CustomFieldValue adalah nilai custom field pada sebuah task, nilai disimpan dalam bentuk yang sudah dinormalisasi:
+// number tanpa nol di belakang koma, date dalam RFC3339 UTC dan user berupa user id
+type CustomFieldValue struct {
+	ID            uint64       `json:"id" gorm:"primaryKey"`
+	TaskID        uint64       `json:"task_id" gorm:"uniqueIndex:idx_task_field"`
+	CustomFieldID uint64       `json:"custom_field_id" gorm:"uniqueIndex:idx_task_field;index"`
+	CustomField   *CustomField `json:"custom_field,omitempty" gorm:"foreignKey:CustomFieldID;references:ID"`
+	Value         string       `json:"value" gorm:"type:text"`
+	CreatedAt     time.Time    `json:"-"`
+	UpdatedAt     time.Time    `json:"-"`
+}
+
+// IsValidCustomFieldType mengecek tipe custom field yang didukung
+func IsValidCustomFieldType(fieldType string) bool {
+	switch fieldType {
+	case CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeDate, CustomFieldTypeSelect, CustomFieldTypeUser:
+		return true
+	}
+	return false
+}
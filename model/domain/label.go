@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+const DefaultLabelColor = "#9e9e9e"
+
+// Label adalah kategori task pada level workspace, nama label unik di dalam satu workspace
+type Label struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_label"`
+	Name        string    `json:"name" gorm:"size:50;uniqueIndex:idx_workspace_label"`
+	Color       string    `json:"color" gorm:"size:7"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+}
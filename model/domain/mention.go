@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+const (
+	MentionSourceComment             = "comment"
+	MentionSourcePlanningDescription = "planning_description"
+)
+
+// Mention mencatat user yang di-mention pada comment atau planning description task
+type Mention struct {
+	ID              uint64    `json:"id" gorm:"primaryKey"`
+	TaskID          uint64    `json:"task_id" gorm:"index"`
+	CommentID       *uint64   `json:"comment_id" gorm:"index"`
+	Source          string    `json:"source" gorm:"size:30"`
+	MentionedUserID uint64    `json:"mentioned_user_id" gorm:"index"`
+	MentionedByID   uint64    `json:"mentioned_by_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
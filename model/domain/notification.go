@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+const (
+	NotificationTypeMention  = "mention"
+	NotificationTypePlanning = "planning"
+	NotificationTypeReview   = "review"
+)
+
+// Notification adalah notifikasi in-app untuk user, notifikasi yang sama juga dikirim melalui email
+type Notification struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	UserID    uint64     `json:"user_id" gorm:"index"`
+	TaskID    uint64     `json:"task_id" gorm:"index"`
+	Type      string     `json:"type" gorm:"size:50"`
+	Message   string     `json:"message" gorm:"type:text"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type PasswordReset struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	UserID    uint64     `json:"user_id"`
+	User      User       `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	TokenHash string     `json:"-" gorm:"size:64;unique"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
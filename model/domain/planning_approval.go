@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// PlanningApproval adalah satu putaran pengajuan planning, setiap submit membuat putaran baru.
+// Status putaran mengikuti status planning: submitted, approved atau rejected, SubmittedByID kosong jika user sudah dihapus
+type PlanningApproval struct {
+	ID            uint64     `json:"id" gorm:"primaryKey"`
+	TaskID        uint64     `json:"task_id" gorm:"uniqueIndex:idx_task_planning_round"`
+	Round         int        `json:"round" gorm:"uniqueIndex:idx_task_planning_round"`
+	Status        string     `json:"status" gorm:"size:20"`
+	SubmittedByID *uint64    `json:"submitted_by_id"`
+	SubmittedBy   *User      `json:"submitted_by,omitempty" gorm:"foreignKey:SubmittedByID;references:ID"`
+	Note          string     `json:"note" gorm:"type:text"`
+	DecidedByID   *uint64    `json:"decided_by_id"`
+	DecidedBy     *User      `json:"decided_by,omitempty" gorm:"foreignKey:DecidedByID;references:ID"`
+	Reason        string     `json:"reason" gorm:"type:text"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"-"`
+}
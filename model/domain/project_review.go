@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+const (
+	ProjectReviewPending          = "pending"
+	ProjectReviewAccepted         = "accepted"
+	ProjectReviewChangesRequested = "changes_requested"
+)
+
+// ProjectReview adalah satu putaran review project yang diajukan employee,
+// Files berisi project file yang diunggah sejak putaran sebelumnya, SubmittedByID kosong jika user sudah dihapus
+type ProjectReview struct {
+	ID            uint64        `json:"id" gorm:"primaryKey"`
+	TaskID        uint64        `json:"task_id" gorm:"uniqueIndex:idx_task_review_round"`
+	Round         int           `json:"round" gorm:"uniqueIndex:idx_task_review_round"`
+	Status        string        `json:"status" gorm:"size:20"`
+	SubmittedByID *uint64       `json:"submitted_by_id"`
+	SubmittedBy   *User         `json:"submitted_by,omitempty" gorm:"foreignKey:SubmittedByID;references:ID"`
+	Note          string        `json:"note" gorm:"type:text"`
+	Files         []ProjectFile `json:"files" gorm:"many2many:project_review_files"`
+	ReviewedByID  *uint64       `json:"reviewed_by_id"`
+	ReviewedBy    *User         `json:"reviewed_by,omitempty" gorm:"foreignKey:ReviewedByID;references:ID"`
+	Comment       string        `json:"comment" gorm:"type:text"`
+	ReviewedAt    *time.Time    `json:"reviewed_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"-"`
+}
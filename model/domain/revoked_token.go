@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// RevokedToken menyimpan jti access token yang sudah di-logout sampai token tersebut kadaluarsa
+type RevokedToken struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	Jti       string    `json:"jti" gorm:"size:64;unique"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"-"`
+}
@@ -5,22 +5,81 @@ import (
 )
 
 type Task struct {
-	ID                  uint64         `json:"id" gorm:"primaryKey"`
-	OwnerID             uint64         `json:"-"`
-	Owner               Owner          `json:"owner" gorm:"foreignKey:OwnerID;references:ID"`
-	Manager             []Manager      `json:"manager" gorm:"many2many:task_managers"`
-	Employee            []Employee     `json:"employee" gorm:"many2many:task_employees"`
-	NameTask            string         `json:"name_task" gorm:"size:255"`
-	PlanningDescription string         `json:"planning_description"`
-	PlanningFile        []PlanningFile `json:"planning_file"  gorm:"many2many:task_planning_files"`
-	PlanningStatus      string         `json:"planning_status" gorm:"type:enum('approved','not_approved')"`
-	ProjectFile         []ProjectFile  `json:"project_file"  gorm:"many2many:task_project_files"`
-	ProjectStatus       string         `json:"project_status" gorm:"type:enum('done','undone','working')"`
-	PlanningDueDate     string         `json:"planning_due_date" gorm:"size:255"`
-	ProjectDueDate      string         `json:"project_due_date" gorm:"size:255"`
-	Priority            string         `json:"priority" gorm:"type:enum('high','medium','low')"`
-	ProjectComment      string         `json:"project_comment"`
-	CreatedAt           time.Time      `json:"-"`
-	UpdatedAt           time.Time      `json:"-"`
-	DeletedAt           time.Time      `json:"-"`
+	ID                  uint64             `json:"id" gorm:"primaryKey"`
+	WorkspaceID         uint64             `json:"workspace_id" gorm:"index"`
+	ParentID            *uint64            `json:"parent_id" gorm:"index"`
+	Position            int                `json:"position"`
+	Subtasks            []Task             `json:"subtasks,omitempty" gorm:"foreignKey:ParentID;references:ID"`
+	BlockedBy           []TaskDependency   `json:"blocked_by,omitempty" gorm:"foreignKey:TaskID;references:ID"`
+	Labels              []Label            `json:"labels" gorm:"many2many:task_labels"`
+	CustomFields        []CustomFieldValue `json:"custom_fields" gorm:"foreignKey:TaskID;references:ID"`
+	Checklist           []ChecklistItem    `json:"checklist" gorm:"foreignKey:TaskID;references:ID"`
+	Recurrence          *TaskRecurrence    `json:"recurrence,omitempty" gorm:"foreignKey:TaskID;references:ID"`
+	RecurrenceSourceID  *uint64            `json:"recurrence_source_id" gorm:"index"`
+	Members             []TaskMember       `json:"members" gorm:"foreignKey:TaskID;references:ID"`
+	NameTask            string             `json:"name_task" gorm:"size:255"`
+	PlanningDescription string             `json:"planning_description"`
+	PlanningFile        []PlanningFile     `json:"planning_file"  gorm:"many2many:task_planning_files"`
+	PlanningStatus      string             `json:"planning_status" gorm:"type:enum('approved','not_approved','submitted','rejected')"`
+	ProjectFile         []ProjectFile      `json:"project_file"  gorm:"many2many:task_project_files"`
+	ProjectStatus       string             `json:"project_status" gorm:"type:enum('done','undone','working','in_review','changes_requested')"`
+	PlanningDueDate     *time.Time         `json:"planning_due_date"`
+	ProjectDueDate      *time.Time         `json:"project_due_date"`
+	Priority            string             `json:"priority" gorm:"type:enum('high','medium','low')"`
+	CreatedAt           time.Time          `json:"-"`
+	UpdatedAt           time.Time          `json:"-"`
+	DeletedAt           time.Time          `json:"-"`
+}
+
+// MembersByRole mengambil member task dengan role tertentu, Members harus sudah di-preload
+func (t *Task) MembersByRole(role string) []TaskMember {
+	members := []TaskMember{}
+	for _, member := range t.Members {
+		if member.Role == role {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// Owner mengambil owner task, Members harus sudah di-preload
+func (t *Task) Owner() *TaskMember {
+	for i := range t.Members {
+		if t.Members[i].Role == TaskRoleOwner {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
+
+// Progress menghitung persentase subtask yang project status-nya done, nil jika task tidak memiliki subtask.
+// Subtasks harus sudah di-preload
+func (t *Task) Progress() *float64 {
+	if len(t.Subtasks) == 0 {
+		return nil
+	}
+	done := 0
+	for _, subtask := range t.Subtasks {
+		if subtask.ProjectStatus == ProjectStatusDone {
+			done++
+		}
+	}
+	progress := float64(done) * 100 / float64(len(t.Subtasks))
+	return &progress
+}
+
+// ChecklistCompletion menghitung persentase checklist item yang sudah done, nil jika task tidak memiliki checklist.
+// Checklist harus sudah di-preload
+func (t *Task) ChecklistCompletion() *float64 {
+	if len(t.Checklist) == 0 {
+		return nil
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	completion := float64(done) * 100 / float64(len(t.Checklist))
+	return &completion
 }
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+const (
+	ActivityTaskCreated            = "task_created"
+	ActivityTaskUpdated            = "task_updated"
+	ActivityTaskDeleted            = "task_deleted"
+	ActivitySubtaskAdded           = "subtask_added"
+	ActivitySubtasksReordered      = "subtasks_reordered"
+	ActivityDependencyAdded        = "dependency_added"
+	ActivityDependencyRemoved      = "dependency_removed"
+	ActivityLabelAttached          = "label_attached"
+	ActivityLabelDetached          = "label_detached"
+	ActivityCustomFieldSet         = "custom_field_set"
+	ActivityCustomFieldCleared     = "custom_field_cleared"
+	ActivityChecklistItemAdded     = "checklist_item_added"
+	ActivityChecklistItemUpdated   = "checklist_item_updated"
+	ActivityChecklistItemDeleted   = "checklist_item_deleted"
+	ActivityChecklistReordered     = "checklist_reordered"
+	ActivityRecurrenceSet          = "recurrence_set"
+	ActivityRecurrenceRemoved      = "recurrence_removed"
+	ActivityRecurrenceOccurred     = "recurrence_occurred"
+	ActivityPlanningSubmitted      = "planning_submitted"
+	ActivityPlanningApproved       = "planning_approved"
+	ActivityPlanningRejected       = "planning_rejected"
+	ActivityReviewSubmitted        = "review_submitted"
+	ActivityReviewAccepted         = "review_accepted"
+	ActivityReviewChangesRequested = "review_changes_requested"
+	ActivityMemberAdded            = "member_added"
+	ActivityMemberInvited          = "member_invited"
+	ActivityMemberRoleChanged      = "member_role_changed"
+	ActivityMemberRemoved          = "member_removed"
+	ActivityOwnershipTransferred   = "ownership_transferred"
+	ActivityInvitationDeleted      = "invitation_deleted"
+	ActivityFileUploaded           = "file_uploaded"
+	ActivityFileDeleted            = "file_deleted"
+)
+
+const DefaultActivityPageLimit = 50
+
+// TaskActivity adalah catatan perubahan task yang hanya bisa ditambah, tidak pernah diubah atau dihapus.
+// ActorID kosong berarti perubahan dilakukan oleh sistem, misalnya scheduler task berulang
+type TaskActivity struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	TaskID      uint64    `json:"task_id" gorm:"index"`
+	WorkspaceID uint64    `json:"workspace_id" gorm:"index"`
+	ActorID     *uint64   `json:"actor_id"`
+	Actor       *User     `json:"actor,omitempty" gorm:"foreignKey:ActorID;references:ID"`
+	Action      string    `json:"action" gorm:"size:50;index"`
+	Field       string    `json:"field" gorm:"size:100"`
+	OldValue    string    `json:"old_value" gorm:"type:text"`
+	NewValue    string    `json:"new_value" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// ActivityFilter berisi parameter pagination untuk daftar aktivitas, tidak disimpan ke database
+type ActivityFilter struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Action string `query:"action"`
+}
+
+// Offset menghitung offset query berdasarkan page dan limit
+func (f *ActivityFilter) Offset() int {
+	return (f.Page - 1) * f.Limit
+}
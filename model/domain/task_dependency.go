@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// TaskDependency menyatakan bahwa task TaskID tidak bisa dikerjakan sebelum task BlockedByID selesai
+type TaskDependency struct {
+	ID            uint64    `json:"id" gorm:"primaryKey"`
+	TaskID        uint64    `json:"task_id" gorm:"uniqueIndex:idx_task_blocked_by"`
+	BlockedByID   uint64    `json:"blocked_by_id" gorm:"uniqueIndex:idx_task_blocked_by;index"`
+	Task          *Task     `json:"task,omitempty" gorm:"foreignKey:TaskID;references:ID"`
+	BlockedByTask *Task     `json:"blocked_by_task,omitempty" gorm:"foreignKey:BlockedByID;references:ID"`
+	CreatedByID   uint64    `json:"created_by_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// TaskFilter berisi parameter pagination, filter dan sorting untuk daftar task, tidak disimpan ke database
+type TaskFilter struct {
+	Page            int    `query:"page" validate:"omitempty,min=1"`
+	Limit           int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	PlanningStatus  string `query:"planning_status" validate:"omitempty,oneof=approved not_approved submitted rejected"`
+	ProjectStatus   string `query:"project_status" validate:"omitempty,oneof=done undone working in_review changes_requested"`
+	Priority        string `query:"priority" validate:"omitempty,oneof=high medium low"`
+	PlanningDueFrom string `query:"planning_due_from"`
+	PlanningDueTo   string `query:"planning_due_to"`
+	ProjectDueFrom  string `query:"project_due_from"`
+	ProjectDueTo    string `query:"project_due_to"`
+	OwnerID         uint64 `query:"owner_id"`
+	ManagerID       uint64 `query:"manager_id"`
+	EmployeeID      uint64 `query:"employee_id"`
+	Sort            string `query:"sort"`
+	// Label berisi nama label dan LabelID berisi id label, beberapa label dipisahkan koma dan task harus memiliki semuanya
+	Label   string `query:"label"`
+	LabelID string `query:"label_id"`
+	// LabelIDs berisi id label dari LabelID yang sudah divalidasi
+	LabelIDs []uint64 `query:"-"`
+	// CustomFields berisi filter nilai custom field berdasarkan id custom field, diisi dari query cf_<id>=<value>
+	CustomFields map[uint64]string `query:"-"`
+	// MemberID membatasi task pada user yang menjadi member, Role membatasi role user tersebut
+	MemberID uint64 `query:"-"`
+	Role     string `query:"role" validate:"omitempty,oneof=owner manager employee"`
+	// IncludeSubtasks menampilkan subtask pada daftar task, secara default hanya task utama yang ditampilkan
+	IncludeSubtasks bool `query:"include_subtasks"`
+}
+
+const (
+	DefaultTaskPageLimit = 20
+	// DueSoonWindow adalah batas waktu sebelum due date sehingga task dianggap due soon
+	DueSoonWindow = 3 * 24 * time.Hour
+)
+
+// Offset menghitung offset query berdasarkan page dan limit
+func (f *TaskFilter) Offset() int {
+	return (f.Page - 1) * f.Limit
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// TaskInvitation adalah undangan untuk email yang belum terdaftar, role akan diberikan setelah email tersebut signup dan terverifikasi
+type TaskInvitation struct {
+	ID          uint64     `json:"id" gorm:"primaryKey"`
+	TaskID      uint64     `json:"task_id" gorm:"index"`
+	Email       string     `json:"email" gorm:"size:255;index" validate:"email"`
+	Role        string     `json:"role" gorm:"size:20"`
+	InvitedByID uint64     `json:"invited_by_id"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"-"`
+}
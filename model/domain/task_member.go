@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+const (
+	TaskRoleOwner    = "owner"
+	TaskRoleManager  = "manager"
+	TaskRoleEmployee = "employee"
+)
+
+// TaskMember menggantikan tabel owners, managers dan employees. Satu user hanya bisa memiliki satu role pada satu task.
+type TaskMember struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TaskID    uint64    `json:"task_id" gorm:"uniqueIndex:idx_task_user"`
+	UserID    uint64    `json:"user_id" gorm:"uniqueIndex:idx_task_user"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	Email     string    `json:"email" gorm:"size:255" validate:"email"`
+	Role      string    `json:"role" gorm:"size:20;index"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+	DeletedAt time.Time `json:"-"`
+}
+
+// IsValidTaskRole mengecek role yang dikenal, role baru cukup ditambahkan pada daftar ini
+func IsValidTaskRole(role string) bool {
+	switch role {
+	case TaskRoleOwner, TaskRoleManager, TaskRoleEmployee:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+const (
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// TaskRecurrence adalah aturan pengulangan task, setiap occurrence membuat salinan task sumber beserta member dan deskripsinya.
+// Pengulangan berhenti setelah Until terlewati atau jumlah occurrence mencapai Count
+type TaskRecurrence struct {
+	ID              uint64     `json:"id" gorm:"primaryKey"`
+	TaskID          uint64     `json:"task_id" gorm:"uniqueIndex"`
+	Frequency       string     `json:"frequency" gorm:"size:10"`
+	Interval        int        `json:"interval" gorm:"default:1"`
+	StartAt         time.Time  `json:"start_at"`
+	Until           *time.Time `json:"until"`
+	Count           *int       `json:"count"`
+	OccurrenceCount int        `json:"occurrence_count"`
+	NextRunAt       *time.Time `json:"next_run_at" gorm:"index"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	CreatedByID     uint64     `json:"created_by_id"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"-"`
+}
+
+// Occurrence menghitung waktu occurrence ke-n (dimulai dari 1), occurrence dihitung dari StartAt agar tanggal tidak bergeser
+func (r *TaskRecurrence) Occurrence(n int) time.Time {
+	return r.Advance(r.StartAt, n)
+}
+
+// Advance memajukan waktu sebanyak n periode sesuai frekuensi dan interval
+func (r *TaskRecurrence) Advance(date time.Time, n int) time.Time {
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return date.AddDate(0, 0, n*r.Interval)
+	case RecurrenceWeekly:
+		return date.AddDate(0, 0, 7*n*r.Interval)
+	default:
+		// tanggal dibatasi pada hari terakhir bulan tujuan agar tidak meluap ke bulan berikutnya, contoh: 31 Januari + 1 bulan = 29 Februari
+		year, month, day := date.Date()
+		targetMonth := month + time.Month(n*r.Interval)
+		if lastDay := time.Date(year, targetMonth+1, 0, 0, 0, 0, 0, date.Location()).Day(); day > lastDay {
+			day = lastDay
+		}
+		return time.Date(year, targetMonth, day, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+	}
+}
+
+// Ended mengecek apakah occurrence berikutnya sudah melewati batas Until atau Count
+func (r *TaskRecurrence) Ended(next time.Time) bool {
+	if r.Count != nil && r.OccurrenceCount >= *r.Count {
+		return true
+	}
+	return r.Until != nil && next.After(*r.Until)
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskRecurrenceOccurrence(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency string
+		interval  int
+		n         int
+		want      time.Time
+	}{
+		{"daily first occurrence", RecurrenceDaily, 1, 1, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"daily every three days", RecurrenceDaily, 3, 2, time.Date(2024, 1, 21, 9, 0, 0, 0, time.UTC)},
+		{"daily crosses month", RecurrenceDaily, 1, 20, time.Date(2024, 2, 4, 9, 0, 0, 0, time.UTC)},
+		{"weekly first occurrence", RecurrenceWeekly, 1, 1, time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC)},
+		{"weekly every two weeks", RecurrenceWeekly, 2, 3, time.Date(2024, 2, 26, 9, 0, 0, 0, time.UTC)},
+		{"monthly first occurrence", RecurrenceMonthly, 1, 1, time.Date(2024, 2, 15, 9, 0, 0, 0, time.UTC)},
+		{"monthly crosses year", RecurrenceMonthly, 1, 12, time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"quarterly", RecurrenceMonthly, 3, 2, time.Date(2024, 7, 15, 9, 0, 0, 0, time.UTC)},
+		{"zero occurrence is start", RecurrenceWeekly, 1, 0, start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence := &TaskRecurrence{Frequency: tt.frequency, Interval: tt.interval, StartAt: start}
+			if got := recurrence.Occurrence(tt.n); !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaskRecurrenceOccurrenceDoesNotDrift(t *testing.T) {
+	// tanggal akhir bulan dibatasi pada hari terakhir bulan pendek, lalu kembali ke tanggal awal pada bulan berikutnya
+	tests := []struct {
+		name     string
+		startDay int
+		interval int
+		n        int
+		want     time.Time
+	}{
+		{"31st into leap february", 31, 1, 1, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"31st into march", 31, 1, 2, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"31st into april", 31, 1, 3, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{"31st into may", 31, 1, 4, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"31st into non leap february", 31, 1, 13, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"30th into february", 30, 1, 1, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"29th into non leap february", 29, 1, 13, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"31st every three months", 31, 3, 1, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence := &TaskRecurrence{Frequency: RecurrenceMonthly, Interval: tt.interval, StartAt: time.Date(2024, 1, tt.startDay, 0, 0, 0, 0, time.UTC)}
+			if got := recurrence.Occurrence(tt.n); !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaskRecurrenceEnded(t *testing.T) {
+	until := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	count := 3
+
+	tests := []struct {
+		name            string
+		until           *time.Time
+		count           *int
+		occurrenceCount int
+		next            time.Time
+		want            bool
+	}{
+		{"no limit", nil, nil, 100, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"before until", &until, nil, 0, time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), false},
+		{"exactly at until", &until, nil, 0, until, false},
+		{"after until", &until, nil, 0, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), true},
+		{"count not reached", nil, &count, 2, until, false},
+		{"count reached", nil, &count, 3, until, true},
+		{"count reached before until", &until, &count, 3, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), true},
+		{"until passed before count", &until, &count, 1, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence := &TaskRecurrence{Until: tt.until, Count: tt.count, OccurrenceCount: tt.occurrenceCount}
+			if got := recurrence.Ended(tt.next); got != tt.want {
+				t.Fatalf("expected ended %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// TaskTemplate menyimpan isian task yang sering dipakai berulang pada satu workspace.
+// Due date disimpan sebagai jumlah hari setelah task dibuat
+type TaskTemplate struct {
+	ID                    uint64    `json:"id" gorm:"primaryKey"`
+	WorkspaceID           uint64    `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_template"`
+	Name                  string    `json:"name" gorm:"size:100;uniqueIndex:idx_workspace_template"`
+	NameTask              string    `json:"name_task" gorm:"size:255"`
+	PlanningDescription   string    `json:"planning_description" gorm:"type:text"`
+	Priority              string    `json:"priority" gorm:"size:10"`
+	Managers              []string  `json:"managers" gorm:"type:text;serializer:json"`
+	Employees             []string  `json:"employees" gorm:"type:text;serializer:json"`
+	Checklist             []string  `json:"checklist" gorm:"type:text;serializer:json"`
+	PlanningDueOffsetDays *int      `json:"planning_due_offset_days"`
+	ProjectDueOffsetDays  *int      `json:"project_due_offset_days"`
+	CreatedByID           uint64    `json:"created_by_id"`
+	CreatedAt             time.Time `json:"-"`
+	UpdatedAt             time.Time `json:"-"`
+}
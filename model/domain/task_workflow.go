@@ -0,0 +1,96 @@
+package domain
+
+const (
+	PlanningStatusNotApproved = "not_approved"
+	PlanningStatusSubmitted   = "submitted"
+	PlanningStatusApproved    = "approved"
+	PlanningStatusRejected    = "rejected"
+)
+
+const (
+	ProjectStatusUndone           = "undone"
+	ProjectStatusWorking          = "working"
+	ProjectStatusInReview         = "in_review"
+	ProjectStatusChangesRequested = "changes_requested"
+	ProjectStatusDone             = "done"
+)
+
+// StatusTransitions berisi perpindahan status yang diizinkan: status asal -> status tujuan -> role yang boleh memindahkan
+type StatusTransitions map[string]map[string][]string
+
+// PlanningTransitions: manager mengajukan planning, owner yang memutuskan approve atau reject
+var PlanningTransitions = StatusTransitions{
+	PlanningStatusNotApproved: {
+		PlanningStatusSubmitted: {TaskRoleManager},
+	},
+	PlanningStatusSubmitted: {
+		PlanningStatusApproved: {TaskRoleOwner},
+		PlanningStatusRejected: {TaskRoleOwner},
+	},
+	PlanningStatusRejected: {
+		PlanningStatusSubmitted: {TaskRoleManager},
+	},
+	PlanningStatusApproved: {
+		PlanningStatusNotApproved: {TaskRoleOwner},
+	},
+}
+
+// ProjectTransitions: hanya employee yang mengajukan review, manager atau owner yang menerima atau meminta revisi
+var ProjectTransitions = StatusTransitions{
+	ProjectStatusUndone: {
+		ProjectStatusWorking: {TaskRoleOwner, TaskRoleManager, TaskRoleEmployee},
+	},
+	ProjectStatusWorking: {
+		ProjectStatusInReview: {TaskRoleEmployee},
+		ProjectStatusUndone:   {TaskRoleOwner, TaskRoleManager},
+	},
+	ProjectStatusInReview: {
+		ProjectStatusDone:             {TaskRoleOwner, TaskRoleManager},
+		ProjectStatusChangesRequested: {TaskRoleOwner, TaskRoleManager},
+	},
+	ProjectStatusChangesRequested: {
+		ProjectStatusWorking:  {TaskRoleManager, TaskRoleEmployee},
+		ProjectStatusInReview: {TaskRoleEmployee},
+	},
+	ProjectStatusDone: {
+		ProjectStatusWorking: {TaskRoleOwner},
+	},
+}
+
+// IsValidStatus mengecek apakah status dikenal pada daftar transisi
+func (s StatusTransitions) IsValidStatus(status string) bool {
+	_, ok := s[status]
+	return ok
+}
+
+// Roles mengembalikan role yang boleh memindahkan status, ok bernilai false jika transisi tidak diizinkan
+func (s StatusTransitions) Roles(from string, to string) ([]string, bool) {
+	roles, ok := s[from][to]
+	return roles, ok
+}
+
+// CurrentPlanningStatus mengembalikan status planning, task lama yang statusnya kosong dianggap not_approved
+func (t *Task) CurrentPlanningStatus() string {
+	if t.PlanningStatus == "" {
+		return PlanningStatusNotApproved
+	}
+	return t.PlanningStatus
+}
+
+// CurrentProjectStatus mengembalikan status project, task lama yang statusnya kosong dianggap undone
+func (t *Task) CurrentProjectStatus() string {
+	if t.ProjectStatus == "" {
+		return ProjectStatusUndone
+	}
+	return t.ProjectStatus
+}
+
+// MemberRole mengembalikan role user pada task, Members harus sudah di-preload
+func (t *Task) MemberRole(userID uint64) string {
+	for _, member := range t.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
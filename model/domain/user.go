@@ -2,11 +2,23 @@ package domain
 
 import "time"
 
+const (
+	UserRoleAdmin = "admin"
+	UserRoleUser  = "user"
+)
+
 type User struct {
-	ID        uint64    `json:"id" gorm:"primaryKey"`
-	Email     string    `json:"email" gorm:"size:255;unique" validate:"email"`
-	Password  string    `json:"-" gorm:"size:255"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
-	DeletedAt time.Time `json:"-"`
+	ID         uint64     `json:"id" gorm:"primaryKey"`
+	Email      string     `json:"email" gorm:"size:255;unique" validate:"email"`
+	Password   string     `json:"-" gorm:"size:255"`
+	Role       string     `json:"role" gorm:"type:enum('admin','user');default:'user'"`
+	Verified   bool       `json:"verified" gorm:"default:false"`
+	VerifiedAt *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"-"`
+	UpdatedAt  time.Time  `json:"-"`
+	DeletedAt  time.Time  `json:"-"`
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type Workspace struct {
+	ID        uint64            `json:"id" gorm:"primaryKey"`
+	Name      string            `json:"name" gorm:"size:255"`
+	Members   []WorkspaceMember `json:"members,omitempty" gorm:"foreignKey:WorkspaceID;references:ID"`
+	CreatedAt time.Time         `json:"-"`
+	UpdatedAt time.Time         `json:"-"`
+	DeletedAt time.Time         `json:"-"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+const (
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+type WorkspaceMember struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint64    `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_user"`
+	UserID      uint64    `json:"user_id" gorm:"uniqueIndex:idx_workspace_user"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	Email       string    `json:"email" gorm:"size:255" validate:"email"`
+	Role        string    `json:"role" gorm:"type:enum('admin','member');default:'member'"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+	DeletedAt   time.Time `json:"-"`
+}
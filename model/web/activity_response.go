@@ -0,0 +1,15 @@
+package web
+
+import "manajemen_tugas_master/model/domain"
+
+func CreateResponseActivityPage(activities []*domain.TaskActivity, filter *domain.ActivityFilter, total int64) WebResponse {
+	if activities == nil {
+		activities = []*domain.TaskActivity{}
+	}
+	return WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    activities,
+		Meta:    NewPageMeta(filter.Page, filter.Limit, total),
+	}
+}
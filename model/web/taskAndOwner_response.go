@@ -2,17 +2,17 @@ package web
 
 import (
 	"manajemen_tugas_master/model/domain"
+	"time"
 )
 
 type UpdateResponse struct {
-	NameTask            string `json:"name_task,omitempty"`
-	PlanningDescription string `json:"planning_description,omitempty"`
-	PlanningStatus      string `json:"planning_status,omitempty"`
-	ProjectStatus       string `json:"project_status,omitempty"`
-	PlanningDueDate     string `json:"planning_due_date,omitempty"`
-	ProjectDueDate      string `json:"project_due_date,omitempty"`
-	Priority            string `json:"priority,omitempty"`
-	ProjectComment      string `json:"project_comment,omitempty"`
+	NameTask            string     `json:"name_task,omitempty"`
+	PlanningDescription string     `json:"planning_description,omitempty"`
+	PlanningStatus      string     `json:"planning_status,omitempty"`
+	ProjectStatus       string     `json:"project_status,omitempty"`
+	PlanningDueDate     *time.Time `json:"planning_due_date,omitempty"`
+	ProjectDueDate      *time.Time `json:"project_due_date,omitempty"`
+	Priority            string     `json:"priority,omitempty"`
 	Manager             struct {
 		ID     uint64 `json:"id,omitempty"`
 		Email  string `json:"email,omitempty"`
@@ -33,18 +33,78 @@ type UpdateResponse struct {
 		FileUrl  string `json:"file_url,omitempty"`
 		FileName string `json:"file_name,omitempty"`
 	} `json:"project_file,omitempty"`
+	Members     []*domain.TaskMember     `json:"members,omitempty"`
+	Invitations []*domain.TaskInvitation `json:"invitations,omitempty"`
+}
+
+// TaskResponse menampilkan task beserta owner, manager dan employee yang diambil dari members
+type TaskResponse struct {
+	domain.Task
+	Owner    *domain.TaskMember  `json:"owner"`
+	Manager  []domain.TaskMember `json:"manager"`
+	Employee []domain.TaskMember `json:"employee"`
+	Progress *float64            `json:"progress"`
+	// ChecklistCompletion adalah persentase checklist item yang sudah done
+	ChecklistCompletion *float64 `json:"checklist_completion"`
+}
+
+// MyTaskResponse menampilkan task milik user yang sedang login beserta role dan status due date
+type MyTaskResponse struct {
+	TaskResponse
+	Role    string     `json:"role"`
+	DueDate *time.Time `json:"due_date"`
+	DueSoon bool       `json:"due_soon"`
+	Overdue bool       `json:"overdue"`
 }
 
 func CreateResponseTask(taskModel *domain.Task) WebResponse {
 	return WebResponse{
 		Code:    200,
 		Message: "Success",
-		Data: domain.Task{
+		Data:    createTaskResponse(taskModel),
+	}
+}
+
+func CreateResponseTaskList(tasksModel []*domain.Task) WebResponse {
+	return WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    createTaskResponses(tasksModel),
+	}
+}
+
+func CreateResponseTasksPage(tasksModel []*domain.Task, filter *domain.TaskFilter, total int64) WebResponse {
+	return WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    createTaskResponses(tasksModel),
+		Meta:    NewPageMeta(filter.Page, filter.Limit, total),
+	}
+}
+
+func createTaskResponses(tasksModel []*domain.Task) []TaskResponse {
+	tasks := []TaskResponse{}
+	for _, taskModel := range tasksModel {
+		tasks = append(tasks, createTaskResponse(taskModel))
+	}
+	return tasks
+}
+
+func createTaskResponse(taskModel *domain.Task) TaskResponse {
+	return TaskResponse{
+		Task: domain.Task{
 			ID:                  taskModel.ID,
-			OwnerID:             taskModel.OwnerID,
-			Owner:               taskModel.Owner,
-			Manager:             taskModel.Manager,
-			Employee:            taskModel.Employee,
+			WorkspaceID:         taskModel.WorkspaceID,
+			ParentID:            taskModel.ParentID,
+			Position:            taskModel.Position,
+			Subtasks:            taskModel.Subtasks,
+			BlockedBy:           taskModel.BlockedBy,
+			Labels:              taskModel.Labels,
+			CustomFields:        taskModel.CustomFields,
+			Checklist:           taskModel.Checklist,
+			Recurrence:          taskModel.Recurrence,
+			RecurrenceSourceID:  taskModel.RecurrenceSourceID,
+			Members:             taskModel.Members,
 			NameTask:            taskModel.NameTask,
 			PlanningDescription: taskModel.PlanningDescription,
 			PlanningFile:        taskModel.PlanningFile,
@@ -54,35 +114,44 @@ func CreateResponseTask(taskModel *domain.Task) WebResponse {
 			PlanningDueDate:     taskModel.PlanningDueDate,
 			ProjectDueDate:      taskModel.ProjectDueDate,
 			Priority:            taskModel.Priority,
-			ProjectComment:      taskModel.ProjectComment,
 		},
+		Owner:    taskModel.Owner(),
+		Manager:  taskModel.MembersByRole(domain.TaskRoleManager),
+		Employee: taskModel.MembersByRole(domain.TaskRoleEmployee),
+		Progress: taskModel.Progress(),
+
+		ChecklistCompletion: taskModel.ChecklistCompletion(),
 	}
 }
 
-func CreateResponseTasks(tasksModel []*domain.Task) []WebResponse {
-	var response []WebResponse
+func CreateResponseMyTasks(tasksModel []*domain.Task, userID uint64, filter *domain.TaskFilter, total int64) WebResponse {
+	now := time.Now()
+	tasks := []MyTaskResponse{}
 	for _, taskModel := range tasksModel {
-		response = append(response, WebResponse{
-			Code:    200,
-			Message: "Success",
-			Data: domain.Task{
-				ID:                  taskModel.ID,
-				OwnerID:             taskModel.OwnerID,
-				Owner:               taskModel.Owner,
-				Manager:             taskModel.Manager,
-				Employee:            taskModel.Employee,
-				NameTask:            taskModel.NameTask,
-				PlanningDescription: taskModel.PlanningDescription,
-				PlanningFile:        taskModel.PlanningFile,
-				PlanningStatus:      taskModel.PlanningStatus,
-				ProjectFile:         taskModel.ProjectFile,
-				ProjectStatus:       taskModel.ProjectStatus,
-				PlanningDueDate:     taskModel.PlanningDueDate,
-				ProjectDueDate:      taskModel.ProjectDueDate,
-				Priority:            taskModel.Priority,
-				ProjectComment:      taskModel.ProjectComment,
-			},
-		})
+		task := MyTaskResponse{TaskResponse: createTaskResponse(taskModel)}
+		for _, member := range taskModel.Members {
+			if member.UserID == userID {
+				task.Role = member.Role
+			}
+		}
+
+		// due date yang berlaku adalah planning due date selama planning belum approved, setelah itu project due date
+		if taskModel.PlanningStatus != domain.PlanningStatusApproved {
+			task.DueDate = taskModel.PlanningDueDate
+		} else if taskModel.ProjectStatus != domain.ProjectStatusDone {
+			task.DueDate = taskModel.ProjectDueDate
+		}
+		if task.DueDate != nil {
+			task.Overdue = task.DueDate.Before(now)
+			task.DueSoon = !task.Overdue && task.DueDate.Before(now.Add(domain.DueSoonWindow))
+		}
+
+		tasks = append(tasks, task)
+	}
+	return WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    tasks,
+		Meta:    NewPageMeta(filter.Page, filter.Limit, total),
 	}
-	return response
 }
@@ -2,6 +2,7 @@ package web
 
 import (
 	"manajemen_tugas_master/model/domain"
+	"time"
 )
 
 func CreateResponseUser(userModel *domain.User) WebResponse {
@@ -9,8 +10,17 @@ func CreateResponseUser(userModel *domain.User) WebResponse {
 		Code:    200,
 		Message: "Success",
 		Data: domain.User{
-			ID:    userModel.ID,
-			Email: userModel.Email,
+			ID:       userModel.ID,
+			Email:    userModel.Email,
+			Role:     userModel.Role,
+			Verified: userModel.Verified,
 		},
 	}
 }
+
+type TokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
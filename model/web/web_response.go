@@ -4,4 +4,26 @@ type WebResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	Meta    interface{} `json:"meta,omitempty"`
+}
+
+// PageMeta berisi informasi pagination pada response daftar data
+type PageMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func NewPageMeta(page int, limit int, total int64) PageMeta {
+	totalPages := total / int64(limit)
+	if total%int64(limit) != 0 {
+		totalPages++
+	}
+	return PageMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
 }
@@ -0,0 +1,17 @@
+package web
+
+import (
+	"manajemen_tugas_master/model/domain"
+)
+
+func CreateResponseWorkspace(workspaceModel *domain.Workspace) WebResponse {
+	return WebResponse{
+		Code:    200,
+		Message: "Success",
+		Data: domain.Workspace{
+			ID:      workspaceModel.ID,
+			Name:    workspaceModel.Name,
+			Members: workspaceModel.Members,
+		},
+	}
+}
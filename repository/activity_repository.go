@@ -0,0 +1,8 @@
+package repository
+
+import "manajemen_tugas_master/model/domain"
+
+type ActivityRepository interface {
+	FindAllByTask(taskID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error)
+	FindAllByWorkspace(workspaceID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+
+	"gorm.io/gorm"
+)
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepository{db}
+}
+
+func (a *activityRepository) FindAllByTask(taskID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error) {
+	return a.findAll(activityScope("task_id", taskID, filter), filter)
+}
+
+func (a *activityRepository) FindAllByWorkspace(workspaceID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error) {
+	return a.findAll(activityScope("workspace_id", workspaceID, filter), filter)
+}
+
+// activityScope membatasi aktivitas pada satu task atau workspace, column hanya diisi dari kode bukan dari request
+func activityScope(column string, id uint, filter *domain.ActivityFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where(column+" = ?", id)
+		if filter.Action != "" {
+			db = db.Where("action = ?", filter.Action)
+		}
+		return db
+	}
+}
+
+// findAll mengambil aktivitas terbaru terlebih dahulu dengan pagination
+func (a *activityRepository) findAll(scope func(db *gorm.DB) *gorm.DB, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error) {
+	var total int64
+	if err := a.db.Model(&domain.TaskActivity{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count activities: %v", err)
+	}
+
+	var activities []*domain.TaskActivity
+	if err := a.db.Scopes(scope).Preload("Actor").Order("created_at DESC, id DESC").
+		Offset(filter.Offset()).Limit(filter.Limit).
+		Find(&activities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find activities: %v", err)
+	}
+	return activities, total, nil
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "manajemen_tugas_master/model/domain"
+
+type CommentRepository interface {
+	Create(comment *domain.Comment) (*domain.Comment, error)
+	FindById(taskID uint, commentID uint) (*domain.Comment, error)
+	FindAllByTask(taskID uint) ([]*domain.Comment, error)
+	Update(comment *domain.Comment) (*domain.Comment, error)
+	Delete(comment *domain.Comment) ([]domain.CommentAttachment, error)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db}
+}
+
+// commentOrder mengurutkan comment dari yang paling lama
+func commentOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC, id ASC")
+}
+
+func (c *commentRepository) Create(comment *domain.Comment) (*domain.Comment, error) {
+	// attachment ikut tersimpan melalui relasi Attachments
+	if err := c.db.Create(comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to create comment: %v", err)
+	}
+
+	return c.FindById(uint(comment.TaskID), uint(comment.ID))
+}
+
+func (c *commentRepository) FindById(taskID uint, commentID uint) (*domain.Comment, error) {
+	var comment domain.Comment
+	if err := c.db.Preload("Author").Preload("Attachments").
+		Preload("Replies", commentOrder).Preload("Replies.Author").Preload("Replies.Attachments").
+		First(&comment, "id = ? AND task_id = ?", commentID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Comment not found")
+		}
+		return nil, fmt.Errorf("failed to find comment: %v", err)
+	}
+
+	return &comment, nil
+}
+
+func (c *commentRepository) FindAllByTask(taskID uint) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+
+	// hanya comment utama, balasan ditampilkan di dalam comment utama
+	if err := c.db.Scopes(commentOrder).Preload("Author").Preload("Attachments").
+		Preload("Replies", commentOrder).Preload("Replies.Author").Preload("Replies.Attachments").
+		Where("task_id = ? AND parent_id IS NULL", taskID).
+		Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to find comments: %v", err)
+	}
+
+	return comments, nil
+}
+
+func (c *commentRepository) Update(comment *domain.Comment) (*domain.Comment, error) {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(comment).Updates(map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt}).Error; err != nil {
+			return err
+		}
+
+		// attachment baru ditambahkan tanpa menghapus attachment lama
+		for i := range comment.Attachments {
+			if comment.Attachments[i].ID != 0 {
+				continue
+			}
+			comment.Attachments[i].CommentID = comment.ID
+			if err := tx.Create(&comment.Attachments[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %v", err)
+	}
+
+	return c.FindById(uint(comment.TaskID), uint(comment.ID))
+}
+
+func (c *commentRepository) Delete(comment *domain.Comment) ([]domain.CommentAttachment, error) {
+	var attachments []domain.CommentAttachment
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		// comment utama dihapus beserta balasannya
+		commentIDs := []uint64{comment.ID}
+		var replyIDs []uint64
+		if err := tx.Model(&domain.Comment{}).Where("parent_id = ?", comment.ID).Pluck("id", &replyIDs).Error; err != nil {
+			return err
+		}
+		commentIDs = append(commentIDs, replyIDs...)
+
+		if err := tx.Where("comment_id IN ?", commentIDs).Find(&attachments).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id IN ?", commentIDs).Delete(&domain.CommentAttachment{}).Error; err != nil {
+			return err
+		}
+		// mention pada comment yang dihapus ikut dihapus agar tidak menunjuk comment yang sudah tidak ada
+		if err := tx.Where("comment_id IN ?", commentIDs).Delete(&domain.Mention{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", commentIDs).Delete(&domain.Comment{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete comment: %v", err)
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "manajemen_tugas_master/model/domain"
+
+type NotificationRepository interface {
+	Create(notification *domain.Notification) (*domain.Notification, error)
+	FindAllByUser(userID uint, unreadOnly bool) ([]*domain.Notification, error)
+	MarkRead(userID uint, notificationID uint) error
+	MarkAllRead(userID uint) error
+	CreateMention(mention *domain.Mention) (bool, error)
+}
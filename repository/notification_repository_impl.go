@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (n *notificationRepository) Create(notification *domain.Notification) (*domain.Notification, error) {
+	if err := n.db.Create(notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to create notification: %v", err)
+	}
+	return notification, nil
+}
+
+func (n *notificationRepository) FindAllByUser(userID uint, unreadOnly bool) ([]*domain.Notification, error) {
+	var notifications []*domain.Notification
+
+	query := n.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Order("created_at DESC, id DESC").Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to find notifications: %v", err)
+	}
+
+	return notifications, nil
+}
+
+func (n *notificationRepository) MarkRead(userID uint, notificationID uint) error {
+	result := n.db.Model(&domain.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to update notification: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Notification not found")
+	}
+	return nil
+}
+
+func (n *notificationRepository) MarkAllRead(userID uint) error {
+	if err := n.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to update notifications: %v", err)
+	}
+	return nil
+}
+
+// CreateMention menyimpan mention, mengembalikan false jika user sudah pernah di-mention pada sumber yang sama
+func (n *notificationRepository) CreateMention(mention *domain.Mention) (bool, error) {
+	query := n.db.Model(&domain.Mention{}).
+		Where("task_id = ? AND source = ? AND mentioned_user_id = ?", mention.TaskID, mention.Source, mention.MentionedUserID)
+	if mention.CommentID != nil {
+		query = query.Where("comment_id = ?", *mention.CommentID)
+	} else {
+		query = query.Where("comment_id IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if err := n.db.Create(mention).Error; err != nil {
+		return false, fmt.Errorf("failed to create mention: %v", err)
+	}
+	return true, nil
+}
@@ -2,26 +2,57 @@ package repository
 
 import (
 	"manajemen_tugas_master/model/domain"
-
-	"gorm.io/gorm"
+	"time"
 )
 
 type TaskAndOwnerRepository interface {
-	Create(user *domain.User, task *domain.Task) (*domain.Task, *domain.Owner, error)
-	FindById(id uint) (*domain.Task, error)
-	FindAll() ([]*domain.Task, error)
-	FindAllOwners() ([]*domain.Task, error)
-	FindAllManagers() ([]*domain.Task, error)
-	FindAllEmployees() ([]*domain.Task, error)
-	FindAllPlanningFiles() ([]*domain.Task, error)
-	FindAllProjectFiles() ([]*domain.Task, error)
-	Update(task *domain.Task, manager *domain.Manager, employee *domain.Employee, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile) (*domain.Task, *domain.Manager, *domain.Employee, *domain.PlanningFile, *domain.ProjectFile, error)
-	UpdateValidationOwner(taskID uint, userID uint) error
-	UpdateValidationManager(taskID uint, userID uint) error
-	UpdateValidationEmployee(taskID uint, userID uint) error
-	DeleteManager(taskId uint, managerId uint) (*gorm.DB, int64, int64, int64, error)
-	DeleteEmployee(taskId uint, employeeId uint) (*gorm.DB, int64, error)
-	DeletePlanningFile(fileId uint) (*gorm.DB, string, error)
-	DeleteProjectFile(fileId uint) (*gorm.DB, string, error)
-	Delete(taskID uint) (*gorm.DB, int64, int64, int64, int64, int64, error)
+	Transaction(fn func(repo TaskAndOwnerRepository) error) error
+	CreateActivities(activities ...*domain.TaskActivity) error
+	Create(user *domain.User, task *domain.Task) (*domain.Task, *domain.TaskMember, error)
+	FindById(id uint, userID uint) (*domain.Task, error)
+	FindAll(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error)
+	FindAllMembers(userID uint, role string) ([]*domain.Task, error)
+	FindAllPlanningFiles(userID uint) ([]*domain.Task, error)
+	FindAllProjectFiles(userID uint) ([]*domain.Task, error)
+	FindSubtasks(taskID uint) ([]*domain.Task, error)
+	ReorderSubtasks(taskID uint, subtaskIDs []uint) error
+	FindDependencies(taskID uint) ([]*domain.TaskDependency, []*domain.TaskDependency, error)
+	CreateDependency(dependency *domain.TaskDependency) (*domain.TaskDependency, error)
+	DeleteDependency(taskID uint, blockedByID uint) error
+	CountUnfinishedBlockers(taskID uint) (int64, error)
+	AttachLabel(taskID uint, labelID uint) (*domain.Label, error)
+	DetachLabel(taskID uint, labelID uint) error
+	SetCustomFieldValue(value *domain.CustomFieldValue) (*domain.CustomFieldValue, error)
+	DeleteCustomFieldValue(taskID uint, fieldID uint) error
+	CreateChecklistItem(item *domain.ChecklistItem) (*domain.ChecklistItem, error)
+	FindChecklist(taskID uint) ([]*domain.ChecklistItem, error)
+	FindChecklistItem(taskID uint, itemID uint) (*domain.ChecklistItem, error)
+	UpdateChecklistItem(item *domain.ChecklistItem) (*domain.ChecklistItem, error)
+	ReorderChecklist(taskID uint, itemIDs []uint) error
+	DeleteChecklistItem(taskID uint, itemID uint) error
+	SaveRecurrence(recurrence *domain.TaskRecurrence) (*domain.TaskRecurrence, error)
+	DeleteRecurrence(taskID uint) error
+	FindDueRecurrenceIDs(now time.Time) ([]uint64, error)
+	RunRecurrence(recurrenceID uint64, now time.Time) (*domain.Task, error)
+	SubmitPlanning(approval *domain.PlanningApproval) (*domain.PlanningApproval, error)
+	DecidePlanning(taskID uint, status string, decidedByID uint, reason string) (*domain.PlanningApproval, error)
+	FindPlanningApprovals(taskID uint) ([]*domain.PlanningApproval, error)
+	FindUnreviewedProjectFiles(taskID uint) ([]domain.ProjectFile, error)
+	FindProjectFileReviewRound(taskID uint, fileID uint) (int, error)
+	SubmitProjectReview(review *domain.ProjectReview) (*domain.ProjectReview, error)
+	DecideProjectReview(taskID uint, status string, reviewedByID uint, comment string, projectStatus string) (*domain.ProjectReview, error)
+	FindProjectReviews(taskID uint) ([]*domain.ProjectReview, error)
+	FindMember(taskID uint, memberID uint) (*domain.TaskMember, error)
+	Update(task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile) (*domain.Task, []*domain.TaskMember, *domain.PlanningFile, *domain.ProjectFile, error)
+	UpdateValidationRole(taskID uint, userID uint, roles ...string) error
+	UpdateMemberRole(taskID uint, memberID uint, role string) (*domain.TaskMember, error)
+	TransferOwnership(taskID uint, email string) (*domain.TaskMember, *domain.TaskMember, error)
+	DeleteMember(taskId uint, memberId uint) error
+	DeletePlanningFile(taskID uint, fileId uint) (string, error)
+	DeleteProjectFile(taskID uint, fileId uint) (string, error)
+	Delete(taskID uint) ([]string, error)
+	CreateInvitation(invitation *domain.TaskInvitation) (*domain.TaskInvitation, error)
+	FindAllInvitations(taskID uint) ([]*domain.TaskInvitation, error)
+	DeleteInvitation(taskID uint, invitationID uint) error
+	AcceptInvitations(user *domain.User) ([]*domain.TaskInvitation, error)
 }
@@ -3,10 +3,12 @@ package repository
 import (
 	"errors"
 	"fmt"
-	"log"
 	"manajemen_tugas_master/model/domain"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type taskAndOwnerRepository struct {
@@ -14,258 +16,935 @@ type taskAndOwnerRepository struct {
 }
 
 func NewTaskAndOwnerRepository(db *gorm.DB) TaskAndOwnerRepository {
-	return &taskAndOwnerRepository{db}
+	return &taskAndOwnerRepository{db: db}
 }
 
-func (t *taskAndOwnerRepository) Create(user *domain.User, task *domain.Task) (*domain.Task, *domain.Owner, error) {
-	var Owner domain.Owner
-	Owner.Email = user.Email
-	Owner.UserID = user.ID
-	if err := t.db.Create(&Owner).Error; err != nil {
-		return nil, nil, err
+// withTx membuat repository yang semua query-nya berjalan pada transaksi tx
+func (t *taskAndOwnerRepository) withTx(tx *gorm.DB) *taskAndOwnerRepository {
+	return &taskAndOwnerRepository{db: tx}
+}
+
+// Transaction menjalankan fn dalam satu transaksi, semua perubahan dibatalkan jika fn mengembalikan error
+func (t *taskAndOwnerRepository) Transaction(fn func(repo TaskAndOwnerRepository) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(t.withTx(tx))
+	})
+}
+
+// CreateActivities menyimpan aktivitas task, workspace diambil dari task jika belum diisi agar aktivitas tetap muncul
+// pada feed workspace walaupun task-nya sudah dihapus
+func (t *taskAndOwnerRepository) CreateActivities(activities ...*domain.TaskActivity) error {
+	if len(activities) == 0 {
+		return nil
 	}
 
-	task.OwnerID = Owner.ID
-	if err := t.db.Create(&task).Error; err != nil {
+	workspaceIDs := map[uint64]uint64{}
+	for _, activity := range activities {
+		if activity.WorkspaceID != 0 {
+			continue
+		}
+		workspaceID, ok := workspaceIDs[activity.TaskID]
+		if !ok {
+			if err := t.db.Model(&domain.Task{}).Select("workspace_id").Where("id = ?", activity.TaskID).Scan(&workspaceID).Error; err != nil {
+				return fmt.Errorf("failed to find task workspace: %v", err)
+			}
+			workspaceIDs[activity.TaskID] = workspaceID
+		}
+		activity.WorkspaceID = workspaceID
+	}
+
+	if err := t.db.Omit("Actor").Create(&activities).Error; err != nil {
+		return fmt.Errorf("failed to create activity: %v", err)
+	}
+	return nil
+}
+
+// workspaceScope membatasi query task hanya pada workspace yang diikuti oleh user
+func workspaceScope(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		workspaceIDs := db.Session(&gorm.Session{NewDB: true}).
+			Model(&domain.WorkspaceMember{}).
+			Select("workspace_id").
+			Where("user_id = ?", userID)
+		return db.Where("tasks.workspace_id IN (?)", workspaceIDs)
+	}
+}
+
+func (t *taskAndOwnerRepository) Create(user *domain.User, task *domain.Task) (*domain.Task, *domain.TaskMember, error) {
+	var owner domain.TaskMember
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		// subtask baru ditempatkan pada urutan terakhir
+		if task.ParentID != nil {
+			var position int
+			if err := tx.Model(&domain.Task{}).Select("COALESCE(MAX(position), 0)").Where("parent_id = ?", *task.ParentID).Scan(&position).Error; err != nil {
+				return err
+			}
+			task.Position = position + 1
+		}
+
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+
+		// pembuat task otomatis menjadi owner
+		owner.TaskID = task.ID
+		owner.UserID = user.ID
+		owner.Email = user.Email
+		owner.Role = domain.TaskRoleOwner
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		task.Members = []domain.TaskMember{owner}
+
+		return nil
+	})
+	if err != nil {
 		return nil, nil, err
 	}
 
-	return task, &Owner, nil
+	return task, &owner, nil
 }
 
-func (t *taskAndOwnerRepository) FindById(id uint) (*domain.Task, error) {
+func (t *taskAndOwnerRepository) FindById(id uint, userID uint) (*domain.Task, error) {
 	var task domain.Task
-	var owner domain.Owner
 
-	// Mencari data owner
-	if err := t.db.Find(&owner).Error; err != nil {
-		return nil, errors.New("Owner not found")
-	}
 	// Mencari semua data task tertentu dengan semua relasinya
-	if err := t.db.Preload("PlanningFile").Preload("ProjectFile").Preload("Manager").Preload("Employee").First(&task, id).Error; err != nil {
+	if err := t.db.Scopes(workspaceScope(userID)).Preload("Members").Preload("PlanningFile").Preload("ProjectFile").Preload("Subtasks", subtaskOrder).Preload("BlockedBy.BlockedByTask").Preload("Labels").Preload("CustomFields.CustomField").Preload("Checklist", checklistOrder).Preload("Checklist.Assignee").Preload("Recurrence").First(&task, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("Task not found")
 		}
 		return nil, err
 	}
 
-	// Mengisi informasi owner pada task
-	task.Owner = owner
-
 	return &task, nil
 }
 
-func (t *taskAndOwnerRepository) FindAll() ([]*domain.Task, error) {
-	var tasks []*domain.Task
-	var owners []*domain.Owner
+// taskSortColumns berisi field yang boleh digunakan untuk sorting daftar task
+var taskSortColumns = map[string]string{
+	"id":                "tasks.id",
+	"name_task":         "tasks.name_task",
+	"priority":          "tasks.priority",
+	"planning_status":   "tasks.planning_status",
+	"project_status":    "tasks.project_status",
+	"planning_due_date": "tasks.planning_due_date",
+	"project_due_date":  "tasks.project_due_date",
+	"created_at":        "tasks.created_at",
+	"updated_at":        "tasks.updated_at",
+}
 
-	// Mencari semua data owner
-	if err := t.db.Find(&owners).Error; err != nil {
-		return nil, errors.New("Owner not found")
-	}
+// taskFilterScope menerapkan filter daftar task, member difilter berdasarkan user id dan role
+func taskFilterScope(filter *domain.TaskFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !filter.IncludeSubtasks {
+			db = db.Where("tasks.parent_id IS NULL")
+		}
+		if filter.PlanningStatus != "" {
+			db = db.Where("tasks.planning_status = ?", filter.PlanningStatus)
+		}
+		if filter.ProjectStatus != "" {
+			db = db.Where("tasks.project_status = ?", filter.ProjectStatus)
+		}
+		if filter.Priority != "" {
+			db = db.Where("tasks.priority = ?", filter.Priority)
+		}
+		if filter.PlanningDueFrom != "" {
+			db = db.Where("tasks.planning_due_date >= ?", filter.PlanningDueFrom)
+		}
+		if filter.PlanningDueTo != "" {
+			db = db.Where("tasks.planning_due_date <= ?", filter.PlanningDueTo)
+		}
+		if filter.ProjectDueFrom != "" {
+			db = db.Where("tasks.project_due_date >= ?", filter.ProjectDueFrom)
+		}
+		if filter.ProjectDueTo != "" {
+			db = db.Where("tasks.project_due_date <= ?", filter.ProjectDueTo)
+		}
 
-	// Mencari semua data task dengan semua relasinya
-	if err := t.db.Preload("Manager").Preload("Employee").Preload("PlanningFile").Preload("ProjectFile").Find(&tasks).Error; err != nil {
-		return nil, errors.New("Task not found")
-	}
+		if filter.MemberID != 0 {
+			taskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Model(&domain.TaskMember{}).
+				Select("task_id").
+				Where("user_id = ?", filter.MemberID)
+			if filter.Role != "" {
+				taskIDs = taskIDs.Where("role = ?", filter.Role)
+			}
+			db = db.Where("tasks.id IN (?)", taskIDs)
+		}
 
-	// Mengisi informasi owner pada setiap task
-	for _, task := range tasks {
-		for _, owner := range owners {
-			if owner.ID == task.OwnerID {
-				task.Owner = *owner
-				break
+		for _, label := range strings.Split(filter.Label, ",") {
+			label = strings.TrimSpace(label)
+			if label == "" {
+				continue
 			}
+			taskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Table("task_labels").
+				Select("task_labels.task_id").
+				Joins("JOIN labels ON labels.id = task_labels.label_id").
+				Where("labels.name = ?", label)
+			db = db.Where("tasks.id IN (?)", taskIDs)
 		}
-	}
 
-	return tasks, nil
-}
+		for _, labelID := range filter.LabelIDs {
+			taskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Table("task_labels").
+				Select("task_id").
+				Where("label_id = ?", labelID)
+			db = db.Where("tasks.id IN (?)", taskIDs)
+		}
 
-func (t *taskAndOwnerRepository) FindAllOwners() ([]*domain.Task, error) {
-	var tasks []*domain.Task
-	var owners []*domain.Owner
+		for fieldID, value := range filter.CustomFields {
+			taskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Model(&domain.CustomFieldValue{}).
+				Select("task_id").
+				Where("custom_field_id = ? AND value = ?", fieldID, value)
+			db = db.Where("tasks.id IN (?)", taskIDs)
+		}
 
-	// Mencari semua data owner
-	if err := t.db.Find(&owners).Error; err != nil {
-		return nil, errors.New("Owner not found")
-	}
+		memberFilters := map[string]uint64{
+			domain.TaskRoleOwner:    filter.OwnerID,
+			domain.TaskRoleManager:  filter.ManagerID,
+			domain.TaskRoleEmployee: filter.EmployeeID,
+		}
+		for role, userID := range memberFilters {
+			if userID == 0 {
+				continue
+			}
+			taskIDs := db.Session(&gorm.Session{NewDB: true}).
+				Model(&domain.TaskMember{}).
+				Select("task_id").
+				Where("user_id = ? AND role = ?", userID, role)
+			db = db.Where("tasks.id IN (?)", taskIDs)
+		}
 
-	// Mencari semua data task
-	if err := t.db.Find(&tasks).Error; err != nil {
-		return nil, errors.New("Owner not found")
+		return db
 	}
+}
 
-	for _, task := range tasks {
-		for _, owner := range owners {
-			task.Owner = *owner
+// taskSortScope mengurutkan daftar task, awalan "-" untuk urutan descending, contoh: sort=-created_at,priority
+func taskSortScope(sort string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			direction := "ASC"
+			if strings.HasPrefix(field, "-") {
+				direction = "DESC"
+				field = strings.TrimPrefix(field, "-")
+			}
+			if column, ok := taskSortColumns[field]; ok {
+				db = db.Order(column + " " + direction)
+			}
 		}
+		// id sebagai urutan terakhir agar hasil pagination stabil
+		return db.Order("tasks.id ASC")
 	}
+}
 
-	return tasks, nil
+func (t *taskAndOwnerRepository) FindAll(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error) {
+	var (
+		tasks []*domain.Task
+		total int64
+	)
+
+	if err := t.db.Model(&domain.Task{}).Scopes(workspaceScope(userID), taskFilterScope(filter)).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %v", err)
+	}
+
+	// Mencari data task pada halaman yang diminta dengan semua relasinya
+	if err := t.db.Scopes(workspaceScope(userID), taskFilterScope(filter), taskSortScope(filter.Sort)).
+		Preload("Members").Preload("PlanningFile").Preload("ProjectFile").Preload("Subtasks", subtaskOrder).Preload("Labels").Preload("CustomFields.CustomField").Preload("Checklist").
+		Offset(filter.Offset()).Limit(filter.Limit).
+		Find(&tasks).Error; err != nil {
+		return nil, 0, errors.New("Task not found")
+	}
+
+	return tasks, total, nil
 }
 
-func (t *taskAndOwnerRepository) FindAllManagers() ([]*domain.Task, error) {
+func (t *taskAndOwnerRepository) FindAllMembers(userID uint, role string) ([]*domain.Task, error) {
 	var tasks []*domain.Task
 
-	// Mencari semua data task dengan preload untuk Manager
-	if err := t.db.Preload("Manager").Find(&tasks).Error; err != nil {
+	// Mencari semua data task dengan preload untuk member, jika role kosong semua member diambil
+	query := t.db.Scopes(workspaceScope(userID))
+	if role != "" {
+		query = query.Preload("Members", "role = ?", role)
+	} else {
+		query = query.Preload("Members")
+	}
+	if err := query.Find(&tasks).Error; err != nil {
 		return nil, errors.New("Failed to find tasks")
 	}
 
 	return tasks, nil
 }
 
-func (t *taskAndOwnerRepository) FindAllEmployees() ([]*domain.Task, error) {
+func (t *taskAndOwnerRepository) FindAllPlanningFiles(userID uint) ([]*domain.Task, error) {
 	var tasks []*domain.Task
 
-	// Mencari semua data task dengan preload untuk Manager
-	if err := t.db.Preload("Employee").Find(&tasks).Error; err != nil {
+	// Mencari semua data task dengan preload untuk PlanningFile
+	if err := t.db.Scopes(workspaceScope(userID)).Preload("PlanningFile").Find(&tasks).Error; err != nil {
 		return nil, errors.New("Failed to find tasks")
 	}
 
 	return tasks, nil
 }
 
-func (t *taskAndOwnerRepository) FindAllPlanningFiles() ([]*domain.Task, error) {
+func (t *taskAndOwnerRepository) FindAllProjectFiles(userID uint) ([]*domain.Task, error) {
 	var tasks []*domain.Task
 
-	// Mencari semua data task dengan preload untuk Manager
-	if err := t.db.Preload("PlanningFile").Find(&tasks).Error; err != nil {
+	// Mencari semua data task dengan preload untuk ProjectFile
+	if err := t.db.Scopes(workspaceScope(userID)).Preload("ProjectFile").Find(&tasks).Error; err != nil {
 		return nil, errors.New("Failed to find tasks")
 	}
 
 	return tasks, nil
 }
 
-func (t *taskAndOwnerRepository) FindAllProjectFiles() ([]*domain.Task, error) {
-	var tasks []*domain.Task
+// subtaskOrder mengurutkan subtask berdasarkan posisinya
+func subtaskOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
 
-	// Mencari semua data task dengan preload untuk Manager
-	if err := t.db.Preload("ProjectFile").Find(&tasks).Error; err != nil {
-		return nil, errors.New("Failed to find tasks")
+func (t *taskAndOwnerRepository) FindSubtasks(taskID uint) ([]*domain.Task, error) {
+	var subtasks []*domain.Task
+	if err := t.db.Scopes(subtaskOrder).Preload("Members").Preload("Subtasks").Where("parent_id = ?", taskID).Find(&subtasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to find subtasks: %v", err)
 	}
+	return subtasks, nil
+}
 
-	return tasks, nil
+func (t *taskAndOwnerRepository) ReorderSubtasks(taskID uint, subtaskIDs []uint) error {
+	return reorderPositions(t.db, &domain.Task{}, "parent_id", taskID, subtaskIDs, "subtask")
 }
 
-func (t *taskAndOwnerRepository) Update(task *domain.Task, manager *domain.Manager, employee *domain.Employee, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile) (*domain.Task, *domain.Manager, *domain.Employee, *domain.PlanningFile, *domain.ProjectFile, error) {
-	// Simpan task
-	if task.NameTask != "" || task.PlanningDescription != "" || task.PlanningStatus != "" || task.ProjectStatus != "" || task.PlanningDueDate != "" || task.ProjectDueDate != "" || task.Priority != "" || task.ProjectComment != "" {
-		if err := t.db.Save(&task).Error; err != nil {
-			return nil, nil, nil, nil, nil, err
+// reorderPositions mengubah kolom position sesuai urutan ids, ids harus berisi semua baris milik parent tepat satu kali
+func reorderPositions(db *gorm.DB, model interface{}, parentColumn string, parentID uint, orderedIDs []uint, name string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(model).Clauses(clause.Locking{Strength: "UPDATE"}).Where(parentColumn+" = ?", parentID).Pluck("id", &ids).Error; err != nil {
+			return err
 		}
+
+		existing := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			existing[id] = true
+		}
+		if len(orderedIDs) != len(ids) {
+			return fmt.Errorf("%s_ids must contain every %s of the task", name, name)
+		}
+		for _, id := range orderedIDs {
+			if !existing[id] {
+				return fmt.Errorf("%s %d not found or listed twice", name, id)
+			}
+			delete(existing, id)
+		}
+
+		for i, id := range orderedIDs {
+			if err := tx.Model(model).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (t *taskAndOwnerRepository) FindDependencies(taskID uint) ([]*domain.TaskDependency, []*domain.TaskDependency, error) {
+	var blockedBy, blocks []*domain.TaskDependency
+	if err := t.db.Preload("BlockedByTask").Where("task_id = ?", taskID).Find(&blockedBy).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to find dependencies: %v", err)
 	}
+	if err := t.db.Preload("Task").Where("blocked_by_id = ?", taskID).Find(&blocks).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to find dependencies: %v", err)
+	}
+	return blockedBy, blocks, nil
+}
 
-	// Simpan manager
-	if manager != nil && (manager.Email != "") {
-		var user domain.User
-		if err := t.db.First(&user, "email = ?", manager.Email).Error; err != nil {
-			return nil, nil, nil, nil, nil, errors.New("User not found")
+func (t *taskAndOwnerRepository) CreateDependency(dependency *domain.TaskDependency) (*domain.TaskDependency, error) {
+	if dependency.TaskID == dependency.BlockedByID {
+		return nil, errors.New("Task cannot depend on itself")
+	}
+
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var tasks []domain.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&tasks, []uint64{dependency.TaskID, dependency.BlockedByID}).Error; err != nil {
+			return err
+		}
+		if len(tasks) != 2 {
+			return errors.New("Task not found")
+		}
+		if tasks[0].WorkspaceID != tasks[1].WorkspaceID {
+			return errors.New("Dependency must be between tasks in the same workspace")
+		}
+
+		var count int64
+		if err := tx.Model(&domain.TaskDependency{}).Where("task_id = ? AND blocked_by_id = ?", dependency.TaskID, dependency.BlockedByID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("Dependency already exist")
 		}
 
-		// validasi agar ada tidak ada user yang sama pada manager
-		var countManager int64
-		err := t.db.Model(&domain.Manager{}).
-			Where("user_id = ?", user.ID).                                         // Filter by user_id
-			Joins("JOIN task_managers ON task_managers.manager_id = managers.id"). // Join with task_managers
-			Where("task_managers.task_id = ?", task.ID).                           // Filter by task_id
-			Count(&countManager).Error
+		cycle, err := dependencyCreatesCycle(dependency.TaskID, dependency.BlockedByID, func(taskIDs []uint64) ([]uint64, error) {
+			var blockers []uint64
+			err := tx.Model(&domain.TaskDependency{}).Where("task_id IN ?", taskIDs).Pluck("blocked_by_id", &blockers).Error
+			return blockers, err
+		})
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return err
 		}
-		if countManager > 0 {
-			return nil, nil, nil, nil, nil, errors.New("User is already assigned as manager to a task")
+		if cycle {
+			return errors.New("Dependency would create a cycle")
 		}
 
-		// validasi agar user yang telah menjadi employee tidak bisa menjadi manager lagi pada task yang sama.
-		var countEmployee int64
-		err = t.db.Model(&domain.Employee{}).
-			Where("user_id = ?", user.ID).                                             // Filter by user_id
-			Joins("JOIN task_employees ON task_employees.employee_id = employees.id"). // Join with task_employees
-			Where("task_employees.task_id = ?", task.ID).                              // Filter by task_id
-			Count(&countEmployee).Error
+		return tx.Create(dependency).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dependency, nil
+}
+
+// dependencyCreatesCycle mengecek siklus dengan BFS: jika task blocker (secara langsung atau tidak) menunggu task ini,
+// dependency baru akan membentuk siklus. blockersOf mengambil semua task blocker dari sekumpulan task
+func dependencyCreatesCycle(taskID uint64, blockedByID uint64, blockersOf func(taskIDs []uint64) ([]uint64, error)) (bool, error) {
+	if taskID == blockedByID {
+		return true, nil
+	}
+
+	visited := map[uint64]bool{blockedByID: true}
+	queue := []uint64{blockedByID}
+	for len(queue) > 0 {
+		next, err := blockersOf(queue)
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
-		}
-		if countEmployee > 0 {
-			return nil, nil, nil, nil, nil, errors.New("User is already assigned as employee to a task")
-		} else {
-			// jika kedua validasi tersebut berhasil masukkan data ke table penghubung
-			manager.UserID = user.ID
-			if err := t.db.Save(manager).Error; err != nil {
-				return nil, nil, nil, nil, nil, errors.New("Failed to save manager data")
+			return false, err
+		}
+		queue = nil
+		for _, id := range next {
+			if id == taskID {
+				return true, nil
 			}
-			sqlQuery := "INSERT INTO task_managers (task_id, manager_id) VALUES (?, ?)"
-			if err := t.db.Exec(sqlQuery, task.ID, manager.ID).Error; err != nil {
-				return nil, nil, nil, nil, nil, err
+			if !visited[id] {
+				visited[id] = true
+				queue = append(queue, id)
 			}
 		}
 	}
+	return false, nil
+}
 
-	// Simpan employee
-	if employee != nil && (employee.Email != "") {
-		var user domain.User
-		if err := t.db.First(&user, "email = ?", employee.Email).Error; err != nil {
-			return nil, nil, nil, nil, nil, errors.New("User not found")
+func (t *taskAndOwnerRepository) DeleteDependency(taskID uint, blockedByID uint) error {
+	result := t.db.Where("task_id = ? AND blocked_by_id = ?", taskID, blockedByID).Delete(&domain.TaskDependency{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete dependency: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Dependency not found")
+	}
+	return nil
+}
+
+// CountUnfinishedBlockers menghitung task blocker yang project status-nya belum done
+func (t *taskAndOwnerRepository) CountUnfinishedBlockers(taskID uint) (int64, error) {
+	var count int64
+	err := t.db.Model(&domain.TaskDependency{}).
+		Joins("JOIN tasks ON tasks.id = task_dependencies.blocked_by_id").
+		Where("task_dependencies.task_id = ?", taskID).
+		Where("tasks.project_status IS NULL OR tasks.project_status <> ?", domain.ProjectStatusDone).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count blocking tasks: %v", err)
+	}
+	return count, nil
+}
+
+func (t *taskAndOwnerRepository) AttachLabel(taskID uint, labelID uint) (*domain.Label, error) {
+	var task domain.Task
+	if err := t.db.First(&task, taskID).Error; err != nil {
+		return nil, errors.New("Task not found")
+	}
+
+	// label harus berasal dari workspace yang sama dengan task
+	var label domain.Label
+	if err := t.db.First(&label, "id = ? AND workspace_id = ?", labelID, task.WorkspaceID).Error; err != nil {
+		return nil, errors.New("Label not found")
+	}
+
+	var count int64
+	if err := t.db.Table("task_labels").Where("task_id = ? AND label_id = ?", taskID, labelID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Label is already attached to the task")
+	}
+
+	if err := t.db.Model(&task).Association("Labels").Append(&label); err != nil {
+		return nil, fmt.Errorf("failed to attach label: %v", err)
+	}
+	return &label, nil
+}
+
+func (t *taskAndOwnerRepository) DetachLabel(taskID uint, labelID uint) error {
+	result := t.db.Exec("DELETE FROM task_labels WHERE task_id = ? AND label_id = ?", taskID, labelID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to detach label: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Label is not attached to the task")
+	}
+	return nil
+}
+
+// SetCustomFieldValue menyimpan nilai custom field, nilai lama pada task yang sama akan diganti
+func (t *taskAndOwnerRepository) SetCustomFieldValue(value *domain.CustomFieldValue) (*domain.CustomFieldValue, error) {
+	if err := t.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "custom_field_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(value).Error; err != nil {
+		return nil, fmt.Errorf("failed to save custom field value: %v", err)
+	}
+
+	var saved domain.CustomFieldValue
+	if err := t.db.Preload("CustomField").First(&saved, "task_id = ? AND custom_field_id = ?", value.TaskID, value.CustomFieldID).Error; err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (t *taskAndOwnerRepository) DeleteCustomFieldValue(taskID uint, fieldID uint) error {
+	result := t.db.Where("task_id = ? AND custom_field_id = ?", taskID, fieldID).Delete(&domain.CustomFieldValue{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete custom field value: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Custom field value not found")
+	}
+	return nil
+}
+
+// checklistOrder mengurutkan checklist item berdasarkan posisinya
+func checklistOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+func (t *taskAndOwnerRepository) CreateChecklistItem(item *domain.ChecklistItem) (*domain.ChecklistItem, error) {
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		// item baru ditempatkan pada urutan terakhir
+		var position int
+		if err := tx.Model(&domain.ChecklistItem{}).Select("COALESCE(MAX(position), 0)").Where("task_id = ?", item.TaskID).Scan(&position).Error; err != nil {
+			return err
 		}
+		item.Position = position + 1
+		return tx.Create(item).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checklist item: %v", err)
+	}
 
-		// validasi agar ada tidak ada user yang sama pada employee
-		var countEmployee int64
-		err := t.db.Model(&domain.Employee{}).
-			Where("user_id = ?", user.ID).                                             // Filter by user_id
-			Joins("JOIN task_employees ON task_employees.employee_id = employees.id"). // Join with task_employees
-			Where("task_employees.task_id = ?", task.ID).                              // Filter by task_id
-			Count(&countEmployee).Error
-		if err != nil {
-			return nil, nil, nil, nil, nil, err
+	return t.FindChecklistItem(uint(item.TaskID), uint(item.ID))
+}
+
+func (t *taskAndOwnerRepository) FindChecklist(taskID uint) ([]*domain.ChecklistItem, error) {
+	var items []*domain.ChecklistItem
+	if err := t.db.Scopes(checklistOrder).Preload("Assignee").Where("task_id = ?", taskID).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to find checklist: %v", err)
+	}
+	return items, nil
+}
+
+func (t *taskAndOwnerRepository) FindChecklistItem(taskID uint, itemID uint) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	if err := t.db.Preload("Assignee").First(&item, "id = ? AND task_id = ?", itemID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Checklist item not found")
 		}
-		if countEmployee > 0 {
-			return nil, nil, nil, nil, nil, errors.New("User is already assigned as employee to a task")
+		return nil, fmt.Errorf("failed to find checklist item: %v", err)
+	}
+	return &item, nil
+}
+
+func (t *taskAndOwnerRepository) UpdateChecklistItem(item *domain.ChecklistItem) (*domain.ChecklistItem, error) {
+	if err := t.db.Model(item).Select("title", "done", "done_at", "assignee_id").Updates(item).Error; err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %v", err)
+	}
+	return t.FindChecklistItem(uint(item.TaskID), uint(item.ID))
+}
+
+func (t *taskAndOwnerRepository) ReorderChecklist(taskID uint, itemIDs []uint) error {
+	return reorderPositions(t.db, &domain.ChecklistItem{}, "task_id", taskID, itemIDs, "item")
+}
+
+func (t *taskAndOwnerRepository) DeleteChecklistItem(taskID uint, itemID uint) error {
+	result := t.db.Where("id = ? AND task_id = ?", itemID, taskID).Delete(&domain.ChecklistItem{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete checklist item: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Checklist item not found")
+	}
+	return nil
+}
+
+// SaveRecurrence membuat atau mengganti aturan pengulangan task
+func (t *taskAndOwnerRepository) SaveRecurrence(recurrence *domain.TaskRecurrence) (*domain.TaskRecurrence, error) {
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", recurrence.TaskID).Delete(&domain.TaskRecurrence{}).Error; err != nil {
+			return err
 		}
+		return tx.Create(recurrence).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save recurrence: %v", err)
+	}
+	return recurrence, nil
+}
 
-		// validasi agar user yang telah menjadi manager tidak bisa menjadi employee lagi pada task yang sama.
-		var countManager int64
-		err = t.db.Model(&domain.Manager{}).
-			Where("user_id = ?", user.ID).                                         // Filter by user_id
-			Joins("JOIN task_managers ON task_managers.manager_id = managers.id"). // Join with task_managers
-			Where("task_managers.task_id = ?", task.ID).                           // Filter by task_id
-			Count(&countManager).Error
-		if err != nil {
-			return nil, nil, nil, nil, nil, err
-		}
-		if countManager > 0 {
-			return nil, nil, nil, nil, nil, errors.New("User is already assigned as manager to a task")
-		} else {
-			// jika kedua validasi tersebut berhasil masukkan data ke table penghubung
-			employee.UserID = user.ID
-			if err := t.db.Save(employee).Error; err != nil {
-				return nil, nil, nil, nil, nil, errors.New("Failed to save manager data")
+func (t *taskAndOwnerRepository) DeleteRecurrence(taskID uint) error {
+	result := t.db.Where("task_id = ?", taskID).Delete(&domain.TaskRecurrence{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete recurrence: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Recurrence not found")
+	}
+	return nil
+}
+
+func (t *taskAndOwnerRepository) FindDueRecurrenceIDs(now time.Time) ([]uint64, error) {
+	var ids []uint64
+	if err := t.db.Model(&domain.TaskRecurrence{}).Where("next_run_at IS NOT NULL AND next_run_at <= ?", now).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find due recurrences: %v", err)
+	}
+	return ids, nil
+}
+
+// RunRecurrence membuat satu occurrence dari task sumber lalu menjadwalkan occurrence berikutnya.
+// Baris recurrence di-lock agar occurrence yang sama tidak dibuat dua kali jika ada lebih dari satu server
+func (t *taskAndOwnerRepository) RunRecurrence(recurrenceID uint64, now time.Time) (*domain.Task, error) {
+	var clone *domain.Task
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var recurrence domain.TaskRecurrence
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", recurrenceID, now).
+			First(&recurrence).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// sudah diproses oleh server lain
+				return nil
+			}
+			return err
+		}
+
+		var source domain.Task
+		if err := tx.Preload("Members").Preload("Labels").First(&source, recurrence.TaskID).Error; err != nil {
+			return err
+		}
+
+		// due date salinan dimajukan sebanyak jumlah periode sejak task sumber
+		occurrence := recurrence.OccurrenceCount + 1
+		clone = &domain.Task{
+			WorkspaceID:         source.WorkspaceID,
+			NameTask:            source.NameTask,
+			PlanningDescription: source.PlanningDescription,
+			Priority:            source.Priority,
+			RecurrenceSourceID:  &source.ID,
+		}
+		if source.PlanningDueDate != nil {
+			dueDate := recurrence.Advance(*source.PlanningDueDate, occurrence)
+			clone.PlanningDueDate = &dueDate
+		}
+		if source.ProjectDueDate != nil {
+			dueDate := recurrence.Advance(*source.ProjectDueDate, occurrence)
+			clone.ProjectDueDate = &dueDate
+		}
+		if err := tx.Omit("Members", "Labels").Create(clone).Error; err != nil {
+			return err
+		}
+
+		// owner, manager dan employee ikut disalin
+		for _, member := range source.Members {
+			cloneMember := domain.TaskMember{TaskID: clone.ID, UserID: member.UserID, Email: member.Email, Role: member.Role}
+			if err := tx.Create(&cloneMember).Error; err != nil {
+				return err
 			}
-			sqlQuery := "INSERT INTO task_employees (task_id, employee_id) VALUES (?, ?)"
-			if err := t.db.Exec(sqlQuery, task.ID, employee.ID).Error; err != nil {
-				return nil, nil, nil, nil, nil, err
+			clone.Members = append(clone.Members, cloneMember)
+		}
+		if len(source.Labels) > 0 {
+			if err := tx.Model(clone).Association("Labels").Append(source.Labels); err != nil {
+				return err
+			}
+		}
+
+		recurrence.OccurrenceCount = occurrence
+		recurrence.LastRunAt = &now
+		next := recurrence.Occurrence(occurrence + 1)
+		recurrence.NextRunAt = &next
+		if recurrence.Ended(next) {
+			recurrence.NextRunAt = nil
+		}
+		return tx.Model(&recurrence).Select("occurrence_count", "last_run_at", "next_run_at").Updates(&recurrence).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run recurrence: %v", err)
+	}
+
+	return clone, nil
+}
+
+// SubmitPlanning membuat putaran approval baru dan mengubah status planning task menjadi submitted
+func (t *taskAndOwnerRepository) SubmitPlanning(approval *domain.PlanningApproval) (*domain.PlanningApproval, error) {
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		// task di-lock agar nomor putaran tidak bentrok saat submit bersamaan
+		var task domain.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&task, approval.TaskID).Error; err != nil {
+			return err
+		}
+
+		var round int
+		if err := tx.Model(&domain.PlanningApproval{}).Select("COALESCE(MAX(round), 0)").Where("task_id = ?", approval.TaskID).Scan(&round).Error; err != nil {
+			return err
+		}
+		approval.Round = round + 1
+		approval.Status = domain.PlanningStatusSubmitted
+		if err := tx.Create(approval).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&task).Update("planning_status", domain.PlanningStatusSubmitted).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit planning: %v", err)
+	}
+
+	return approval, nil
+}
+
+// DecidePlanning menyimpan keputusan owner pada putaran yang sedang diajukan dan mengubah status planning task
+func (t *taskAndOwnerRepository) DecidePlanning(taskID uint, status string, decidedByID uint, reason string) (*domain.PlanningApproval, error) {
+	var approval domain.PlanningApproval
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("task_id = ? AND status = ?", taskID, domain.PlanningStatusSubmitted).
+			Order("round DESC").First(&approval).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("No planning submission is waiting for a decision")
 			}
+			return err
+		}
+
+		now := time.Now()
+		decidedBy := uint64(decidedByID)
+		approval.Status = status
+		approval.DecidedByID = &decidedBy
+		approval.Reason = reason
+		approval.DecidedAt = &now
+		if err := tx.Model(&approval).Select("status", "decided_by_id", "reason", "decided_at").Updates(&approval).Error; err != nil {
+			return err
 		}
+
+		return tx.Model(&domain.Task{}).Where("id = ?", taskID).Update("planning_status", status).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide planning: %v", err)
+	}
+
+	return &approval, nil
+}
+
+func (t *taskAndOwnerRepository) FindPlanningApprovals(taskID uint) ([]*domain.PlanningApproval, error) {
+	var approvals []*domain.PlanningApproval
+	if err := t.db.Preload("SubmittedBy").Preload("DecidedBy").Where("task_id = ?", taskID).Order("round ASC").Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to find planning approvals: %v", err)
+	}
+	return approvals, nil
+}
+
+// FindUnreviewedProjectFiles mencari project file task yang belum masuk ke putaran review manapun
+func (t *taskAndOwnerRepository) FindUnreviewedProjectFiles(taskID uint) ([]domain.ProjectFile, error) {
+	reviewedFiles := t.db.Session(&gorm.Session{NewDB: true}).Table("project_review_files").
+		Select("project_review_files.project_file_id").
+		Joins("JOIN project_reviews ON project_reviews.id = project_review_files.project_review_id").
+		Where("project_reviews.task_id = ?", taskID)
+
+	var files []domain.ProjectFile
+	if err := t.db.Joins("JOIN task_project_files ON task_project_files.project_file_id = project_files.id").
+		Where("task_project_files.task_id = ? AND project_files.id NOT IN (?)", taskID, reviewedFiles).
+		Order("project_files.id ASC").Find(&files).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project files: %v", err)
+	}
+	return files, nil
+}
+
+// FindProjectFileReviewRound mengembalikan putaran review pertama task yang memuat file, 0 jika file belum pernah diajukan
+func (t *taskAndOwnerRepository) FindProjectFileReviewRound(taskID uint, fileID uint) (int, error) {
+	var round int
+	if err := t.db.Model(&domain.ProjectReview{}).Select("COALESCE(MIN(project_reviews.round), 0)").
+		Joins("JOIN project_review_files ON project_review_files.project_review_id = project_reviews.id").
+		Where("project_reviews.task_id = ? AND project_review_files.project_file_id = ?", taskID, fileID).
+		Scan(&round).Error; err != nil {
+		return 0, fmt.Errorf("failed to find project review: %v", err)
+	}
+	return round, nil
+}
+
+// SubmitProjectReview membuat putaran review baru beserta file-nya dan mengubah status project task menjadi in_review
+func (t *taskAndOwnerRepository) SubmitProjectReview(review *domain.ProjectReview) (*domain.ProjectReview, error) {
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		// task di-lock agar nomor putaran tidak bentrok saat submit bersamaan
+		var task domain.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&task, review.TaskID).Error; err != nil {
+			return err
+		}
+
+		var round int
+		if err := tx.Model(&domain.ProjectReview{}).Select("COALESCE(MAX(round), 0)").Where("task_id = ?", review.TaskID).Scan(&round).Error; err != nil {
+			return err
+		}
+		review.Round = round + 1
+		review.Status = domain.ProjectReviewPending
+		// file sudah ada, hanya relasi project_review_files yang dibuat
+		if err := tx.Omit("Files.*").Create(review).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&task).Update("project_status", domain.ProjectStatusInReview).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit project review: %v", err)
+	}
+
+	return review, nil
+}
+
+// DecideProjectReview menyimpan hasil review pada putaran yang sedang menunggu dan mengubah status project task
+func (t *taskAndOwnerRepository) DecideProjectReview(taskID uint, status string, reviewedByID uint, comment string, projectStatus string) (*domain.ProjectReview, error) {
+	var review domain.ProjectReview
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("task_id = ? AND status = ?", taskID, domain.ProjectReviewPending).
+			Order("round DESC").First(&review).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("No project review is waiting for a decision")
+			}
+			return err
+		}
+
+		now := time.Now()
+		reviewedBy := uint64(reviewedByID)
+		review.Status = status
+		review.ReviewedByID = &reviewedBy
+		review.Comment = comment
+		review.ReviewedAt = &now
+		if err := tx.Model(&review).Select("status", "reviewed_by_id", "comment", "reviewed_at").Updates(&review).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.Task{}).Where("id = ?", taskID).Update("project_status", projectStatus).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review project: %v", err)
+	}
+
+	return &review, nil
+}
+
+func (t *taskAndOwnerRepository) FindProjectReviews(taskID uint) ([]*domain.ProjectReview, error) {
+	var reviews []*domain.ProjectReview
+	if err := t.db.Preload("Files").Preload("SubmittedBy").Preload("ReviewedBy").Where("task_id = ?", taskID).Order("round ASC").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project reviews: %v", err)
+	}
+	return reviews, nil
+}
+
+func (t *taskAndOwnerRepository) FindMember(taskID uint, memberID uint) (*domain.TaskMember, error) {
+	var member domain.TaskMember
+	if err := t.db.First(&member, "id = ? AND task_id = ?", memberID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("member not found")
+		}
+		return nil, fmt.Errorf("failed to find member: %v", err)
+	}
+	return &member, nil
+}
+
+func (t *taskAndOwnerRepository) Update(task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile) (*domain.Task, []*domain.TaskMember, *domain.PlanningFile, *domain.ProjectFile, error) {
+	var savedMembers []*domain.TaskMember
+	// perubahan task, member dan file disimpan dalam satu transaksi agar tidak tersimpan sebagian
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, savedMembers, planningFile, projectFile, err = t.withTx(tx).update(task, members, planningFile, projectFile)
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return task, savedMembers, planningFile, projectFile, nil
+}
+
+// update menyimpan perubahan task, member dan file, harus dipanggil di dalam transaksi
+func (t *taskAndOwnerRepository) update(task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile) (*domain.Task, []*domain.TaskMember, *domain.PlanningFile, *domain.ProjectFile, error) {
+	// Simpan task
+	if task.NameTask != "" || task.PlanningDescription != "" || task.PlanningStatus != "" || task.ProjectStatus != "" || task.PlanningDueDate != nil || task.ProjectDueDate != nil || task.Priority != "" {
+		// hanya field yang diisi yang diupdate, agar field lain tidak tertimpa nilai kosong
+		if err := t.db.Model(task).Updates(task).Error; err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	// Simpan member (manager, employee atau role lainnya)
+	var savedMembers []*domain.TaskMember
+	for _, member := range members {
+		if member == nil || member.Email == "" {
+			continue
+		}
+
+		var user domain.User
+		if err := t.db.First(&user, "email = ?", member.Email).Error; err != nil {
+			return nil, nil, nil, nil, errors.New("User not found")
+		}
+		if !user.Verified {
+			return nil, nil, nil, nil, errors.New("User has not verified their email")
+		}
+		if err := t.validationWorkspaceMember(task.WorkspaceID, user.ID); err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		// validasi agar satu user hanya memiliki satu role pada task yang sama
+		var existing domain.TaskMember
+		err := t.db.First(&existing, "task_id = ? AND user_id = ?", task.ID, user.ID).Error
+		if err == nil {
+			return nil, nil, nil, nil, fmt.Errorf("User is already assigned as %s to a task", existing.Role)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, nil, err
+		}
+
+		// jika validasi berhasil simpan member, unique index task_id dan user_id menjaga dari request bersamaan
+		member.TaskID = task.ID
+		member.UserID = user.ID
+		member.Email = user.Email
+		if err := t.db.Create(member).Error; err != nil {
+			return nil, nil, nil, nil, errors.New("Failed to save member data")
+		}
+		savedMembers = append(savedMembers, member)
 	}
 
 	// Simpan planningFile
 	if planningFile != nil && (planningFile.FileUrl != "" || planningFile.FileName != "") {
 		var count int64
 		if err := t.db.Model(&domain.PlanningFile{}).Where("file_url", planningFile.FileUrl).Count(&count).Error; err != nil {
-			return nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 		if count > 0 {
-			return nil, nil, nil, nil, nil, errors.New("File already exist")
+			return nil, nil, nil, nil, errors.New("File already exist")
 		}
 		if count == 0 {
 			if err := t.db.Save(planningFile).Error; err != nil {
-				return nil, nil, nil, nil, nil, fmt.Errorf("Failed to upload file: %v", err)
+				return nil, nil, nil, nil, fmt.Errorf("Failed to upload file: %v", err)
 			}
 			// Eksekusi query SQL untuk menambahkan relasi task_project_files
 			sqlQuery := "INSERT INTO task_planning_files (task_id, planning_file_id) VALUES (?, ?)"
 			if err := t.db.Exec(sqlQuery, task.ID, planningFile.ID).Error; err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, err
 			}
 		}
 	}
@@ -274,286 +953,247 @@ func (t *taskAndOwnerRepository) Update(task *domain.Task, manager *domain.Manag
 	if projectFile != nil && (projectFile.FileUrl != "" || projectFile.FileName != "") {
 		var count int64
 		if err := t.db.Model(&domain.ProjectFile{}).Where("file_url", projectFile.FileUrl).Count(&count).Error; err != nil {
-			return nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 		if count > 0 {
-			return nil, nil, nil, nil, nil, errors.New("File already exist")
+			return nil, nil, nil, nil, errors.New("File already exist")
 		}
 		if count == 0 {
 			if err := t.db.Save(projectFile).Error; err != nil {
-				return nil, nil, nil, nil, nil, fmt.Errorf("Failed to upload file: %v", err)
+				return nil, nil, nil, nil, fmt.Errorf("Failed to upload file: %v", err)
 			}
 			// Eksekusi query SQL untuk menambahkan relasi task_project_files
 			sqlQuery := "INSERT INTO task_project_files (task_id, project_file_id) VALUES (?, ?)"
 			if err := t.db.Exec(sqlQuery, task.ID, projectFile.ID).Error; err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, err
 			}
 		}
 	}
 
-	return task, manager, employee, planningFile, projectFile, nil
+	return task, savedMembers, planningFile, projectFile, nil
 }
 
-func (t *taskAndOwnerRepository) UpdateValidationOwner(taskID uint, userID uint) error {
-	task, err := t.FindById(taskID)
-	if err != nil {
-		return err
-	}
-
+// validationWorkspaceMember memastikan user yang ditambahkan ke task adalah member workspace dari task tersebut
+func (t *taskAndOwnerRepository) validationWorkspaceMember(workspaceID uint64, userID uint64) error {
 	var count int64
-	if err := t.db.Model(&domain.Owner{}).
-		Where("user_id = ? AND id = ?", userID, task.OwnerID).
+	if err := t.db.Model(&domain.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
 		Count(&count).Error; err != nil {
-		return fmt.Errorf("Gagal validasi kepemilikan: %v", err)
+		return err
 	}
 	if count == 0 {
-		return errors.New("Only for owner")
+		return errors.New("User is not a member of the task workspace")
 	}
-
 	return nil
 }
 
-func (t *taskAndOwnerRepository) UpdateValidationManager(taskID uint, userID uint) error {
+func (t *taskAndOwnerRepository) UpdateValidationRole(taskID uint, userID uint, roles ...string) error {
 	var count int64
-	err := t.db.Model(&domain.Manager{}).
-		Where("user_id = ?", userID).                                          // Filter by user_id
-		Joins("JOIN task_managers ON task_managers.manager_id = managers.id"). // Join with task_managers
-		Where("task_managers.task_id = ?", taskID).                            // Filter by task_id
+	err := t.db.Model(&domain.TaskMember{}).
+		Joins("JOIN tasks ON tasks.id = task_members.task_id"). // Join with tasks agar task di luar workspace user tidak bisa diakses
+		Scopes(workspaceScope(userID)).
+		Where("task_members.task_id = ? AND task_members.user_id = ?", taskID, userID). // Filter by task_id dan user_id
+		Where("task_members.role IN ?", roles).                                         // Filter by role
 		Count(&count).Error
 	if err != nil {
-		return fmt.Errorf("Failed to validate manager: %v", err)
+		return fmt.Errorf("Failed to validate role: %v", err)
 	}
 	if count == 0 {
-		return errors.New("Only for manager")
+		return errors.New("Only for " + strings.Join(roles, " or "))
 	}
 
 	return nil
 }
 
-func (t *taskAndOwnerRepository) UpdateValidationEmployee(taskID uint, userID uint) error {
-	var count int64
-	err := t.db.Model(&domain.Employee{}).
-		Where("user_id = ?", userID).                                              // Filter by user_id
-		Joins("JOIN task_employees ON task_employees.employee_id = employees.id"). // Join with task_employees
-		Where("task_employees.task_id = ?", taskID).                               // Filter by task_id
-		Count(&count).Error
-	if err != nil {
-		return fmt.Errorf("Failed to validate employee: %v", err)
-	}
-	if count == 0 {
-		return errors.New("Only for employee")
-	}
-	return nil
-}
-
-func (t *taskAndOwnerRepository) DeleteManager(taskId uint, managerId uint) (*gorm.DB, int64, int64, int64, error) {
-	var manager domain.Manager
-	if err := t.db.First(&manager, managerId).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, 0, 0, errors.New("manager not found")
+func (t *taskAndOwnerRepository) UpdateMemberRole(taskID uint, memberID uint, role string) (*domain.TaskMember, error) {
+	var member domain.TaskMember
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		// lock baris member agar perubahan role tidak bertabrakan dengan request lain
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&member, "id = ? AND task_id = ?", memberID, taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("member not found")
+			}
+			return fmt.Errorf("failed to find member: %v", err)
+		}
+		if member.Role == domain.TaskRoleOwner {
+			return errors.New("Owner role can only be changed by transferring ownership")
+		}
+		if member.Role == role {
+			return fmt.Errorf("User is already assigned as %s to a task", role)
 		}
-		return nil, 0, 0, 0, fmt.Errorf("failed to find manager: %v", err)
-	}
-
-	sqlQuery := "DELETE FROM task_managers WHERE manager_id = ?"
-	if err := t.db.Exec(sqlQuery, manager.ID).Error; err != nil {
-		return nil, 0, 0, 0, err
-	}
 
-	if err := t.db.Delete(&manager).Error; err != nil {
-		return nil, 0, 0, 0, fmt.Errorf("failed to delete manager: %v", err)
+		// satu user hanya memiliki satu baris member per task, sehingga role lama langsung tergantikan
+		return tx.Model(&member).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Periksa apakah ada manager tersisa untuk task
-	var count int64
-	if err := t.db.Model(&domain.Manager{}).
-		Joins("JOIN task_managers on task_managers.manager_id = managers.id").
-		Where("task_managers.task_id = ?", taskId).
-		Count(&count).Error; err != nil {
-		return nil, 0, 0, 0, err
-	}
+	return &member, nil
+}
 
+func (t *taskAndOwnerRepository) TransferOwnership(taskID uint, email string) (*domain.TaskMember, *domain.TaskMember, error) {
 	var (
-		countEmployee     int64
-		countPlanningFile int64
-		countProjectFile  int64
+		oldOwner domain.TaskMember
+		newOwner domain.TaskMember
 	)
-
-	if count == 0 {
-		// jika tidak ada manager tersisa, hapus semua data employee pada task
-		var taskEmployeeIDs []uint64
-		// menggunakan taskEmployeesQuery.Table untuk count, karena tidak bisa pluck employee_id, dikarenakan tidak ada model dari query ini
-		rows, err := t.db.Raw("SELECT employee_id FROM task_employees WHERE task_id = ?", taskId).Rows()
-		if err != nil {
-			return nil, 0, 0, 0, fmt.Errorf("failed to retrieve task employee IDs: %v", err)
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var task domain.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, taskID).Error; err != nil {
+			return errors.New("Task not found")
 		}
-		defer rows.Close()
 
-		for rows.Next() {
-			var employeeID uint64
-			if err := rows.Scan(&employeeID); err != nil {
-				return nil, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-			}
-			taskEmployeeIDs = append(taskEmployeeIDs, employeeID)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&oldOwner, "task_id = ? AND role = ?", taskID, domain.TaskRoleOwner).Error; err != nil {
+			return errors.New("Owner not found")
 		}
 
-		if len(taskEmployeeIDs) > 0 {
-			taskEmployeesDelete := "DELETE FROM task_employees WHERE task_id = ?"
-			if err := t.db.Exec(taskEmployeesDelete, taskId).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-
-			employeesDelete := "DELETE FROM employees WHERE id IN (?)"
-			if err := t.db.Exec(employeesDelete, taskEmployeeIDs).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-			countEmployee = 1
+		var user domain.User
+		if err := tx.First(&user, "email = ?", email).Error; err != nil {
+			return errors.New("User not found")
 		}
-
-		// jika tidak ada manager tersisa, hapus semua data planning file pada task
-		var taskPlanningFileIDs []uint64
-		// Eksekusi query untuk mengambil planning_file_id
-		rows, err = t.db.Raw("SELECT planning_file_id FROM task_planning_files WHERE task_id = ?", taskId).Rows()
-		if err != nil {
-			return nil, 0, 0, 0, fmt.Errorf("failed to retrieve task planning files IDs: %v", err)
+		if user.ID == oldOwner.UserID {
+			return errors.New("User is already the owner of the task")
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var planningFileID uint64
-			if err := rows.Scan(&planningFileID); err != nil {
-				return nil, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-			}
-			taskPlanningFileIDs = append(taskPlanningFileIDs, planningFileID)
+		if !user.Verified {
+			return errors.New("User has not verified their email")
 		}
 
-		if len(taskPlanningFileIDs) > 0 {
-			taskPlanningFileDelete := "DELETE FROM task_planning_files WHERE task_id = ?"
-			if err := t.db.Exec(taskPlanningFileDelete, taskId).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-
-			planningFileDelete := "DELETE FROM planning_files WHERE id IN (?)"
-			if err := t.db.Exec(planningFileDelete, taskPlanningFileIDs).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-			countPlanningFile = 1
+		var count int64
+		if err := tx.Model(&domain.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", task.WorkspaceID, user.ID).
+			Count(&count).Error; err != nil {
+			return err
 		}
-
-		// Jika tidak ada employee tersisa, hapus semua data project file pada task
-		var taskProjectFileIDs []uint64
-		// Eksekusi query untuk mengambil project_file_id
-		rows, err = t.db.Raw("SELECT project_file_id FROM task_project_files WHERE task_id = ?", taskId).Rows()
-		if err != nil {
-			return nil, 0, 0, 0, fmt.Errorf("failed to retrieve task project files IDs: %v", err)
+		if count == 0 {
+			return errors.New("User is not a member of the task workspace")
 		}
-		defer rows.Close() // Pastikan rows ditutup dengan benar
 
-		// looping hasil query dalam rows yang datanya adalah nilai actual dari project_file_id, kemdian simpan ke dalam projectFileID
-		for rows.Next() {
-			var projectFileID uint64
-			if err := rows.Scan(&projectFileID); err != nil {
-				return nil, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-			}
-			taskProjectFileIDs = append(taskProjectFileIDs, projectFileID)
+		// owner lama menjadi manager
+		if err := tx.Model(&oldOwner).Update("role", domain.TaskRoleManager).Error; err != nil {
+			return err
 		}
 
-		if len(taskProjectFileIDs) > 0 {
-			// Hapus task_project_files jika ada
-			taskProjectFileDelete := "DELETE FROM task_project_files WHERE task_id = ?"
-			if err := t.db.Exec(taskProjectFileDelete, taskId).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-
-			// Hapus project_files menggunakan klausa IN
-			projectFileDelete := "DELETE FROM project_files WHERE id IN (?)"
-			if err := t.db.Exec(projectFileDelete, taskProjectFileIDs).Error; err != nil {
-				return nil, 0, 0, 0, err
-			}
-			countProjectFile = 1
+		// jika user sudah menjadi member, role-nya diubah menjadi owner, jika belum user ditambahkan sebagai owner
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&newOwner, "task_id = ? AND user_id = ?", taskID, user.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			newOwner = domain.TaskMember{TaskID: task.ID, UserID: user.ID, Email: user.Email, Role: domain.TaskRoleOwner}
+			return tx.Create(&newOwner).Error
 		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&newOwner).Update("role", domain.TaskRoleOwner).Error
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return t.db, countEmployee, countPlanningFile, countProjectFile, nil
+	return &oldOwner, &newOwner, nil
 }
 
-func (t *taskAndOwnerRepository) DeleteEmployee(taskId uint, employeeId uint) (*gorm.DB, int64, error) {
-	var employee domain.Employee
-	if err := t.db.First(&employee, employeeId).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, errors.New("employee not found")
-		}
-		return nil, 0, fmt.Errorf("failed to find employee: %v", err)
+func (t *taskAndOwnerRepository) DeleteMember(taskId uint, memberId uint) error {
+	member, err := t.FindMember(taskId, memberId)
+	if err != nil {
+		return err
 	}
-
-	sqlQuery := "DELETE FROM task_employees WHERE employee_id = ?"
-	if err := t.db.Exec(sqlQuery, employee.ID).Error; err != nil {
-		return nil, 0, err
+	if member.Role == domain.TaskRoleOwner {
+		return errors.New("owner cannot be deleted")
 	}
 
-	if err := t.db.Delete(&employee).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to delete employee: %v", err)
+	if err := t.db.Delete(member).Error; err != nil {
+		return fmt.Errorf("failed to delete member: %v", err)
 	}
 
-	// Periksa apakah ada employee tersisa untuk task
+	// Periksa apakah ada member dengan role yang sama tersisa untuk task
 	var count int64
-	if err := t.db.Model(&domain.Employee{}).
-		Joins("JOIN task_employees on task_employees.employee_id = employees.id").
-		Where("task_employees.task_id = ?", taskId).
+	if err := t.db.Model(&domain.TaskMember{}).
+		Where("task_id = ? AND role = ?", taskId, member.Role).
 		Count(&count).Error; err != nil {
-		return nil, 0, err
+		return err
 	}
 
-	var (
-		countProjectFile int64
-	)
-
 	if count == 0 {
-		// Jika tidak ada employee tersisa, hapus semua data project file pada task
-		var taskProjectFileIDs []uint64
-
-		// Eksekusi query untuk mengambil project_file_id
-		rows, err := t.db.Raw("SELECT project_file_id FROM task_project_files WHERE task_id = ?", taskId).Rows()
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to retrieve task project files IDs: %v", err)
-		}
-		defer rows.Close() // Pastikan rows ditutup dengan benar
-
-		// looping hasil query dalam rows yang datanya adalah nilai actual dari project_file_id, kemdian simpan ke dalam projectFileID
-		for rows.Next() {
-			var projectFileID uint64
-			if err := rows.Scan(&projectFileID); err != nil {
-				return nil, 0, fmt.Errorf("failed to read project file ID: %v", err)
+		switch member.Role {
+		case domain.TaskRoleManager:
+			// jika tidak ada manager tersisa, hapus semua employee, planning file dan project file pada task
+			if err := t.db.Where("task_id = ? AND role = ?", taskId, domain.TaskRoleEmployee).Delete(&domain.TaskMember{}).Error; err != nil {
+				return err
 			}
-			taskProjectFileIDs = append(taskProjectFileIDs, projectFileID)
-		}
-
-		if len(taskProjectFileIDs) > 0 {
-			// Hapus task_project_files jika ada
-			taskProjectFileDelete := "DELETE FROM task_project_files WHERE task_id = ?"
-			if err := t.db.Exec(taskProjectFileDelete, taskId).Error; err != nil {
-				return nil, 0, err
+			if err := t.deletePlanningFiles(taskId); err != nil {
+				return err
 			}
-
-			// Hapus project_files menggunakan klausa IN
-			projectFileDelete := "DELETE FROM project_files WHERE id IN (?)"
-			if err := t.db.Exec(projectFileDelete, taskProjectFileIDs).Error; err != nil {
-				return nil, 0, err
+			if err := t.deleteProjectFiles(taskId); err != nil {
+				return err
+			}
+		case domain.TaskRoleEmployee:
+			// jika tidak ada employee tersisa, hapus semua project file pada task
+			if err := t.deleteProjectFiles(taskId); err != nil {
+				return err
 			}
-			countProjectFile = 1
 		}
 	}
 
-	return t.db, countProjectFile, nil
+	return nil
 }
 
-func (t *taskAndOwnerRepository) DeletePlanningFile(fileId uint) (*gorm.DB, string, error) {
+// deletePlanningFiles menghapus semua planning file pada task
+func (t *taskAndOwnerRepository) deletePlanningFiles(taskId uint) error {
+	var taskPlanningFileIDs []uint64
+	// menggunakan Raw karena tabel task_planning_files tidak memiliki model
+	if err := t.db.Raw("SELECT planning_file_id FROM task_planning_files WHERE task_id = ?", taskId).Scan(&taskPlanningFileIDs).Error; err != nil {
+		return fmt.Errorf("failed to retrieve task planning files IDs: %v", err)
+	}
+	if len(taskPlanningFileIDs) == 0 {
+		return nil
+	}
+
+	if err := t.db.Exec("DELETE FROM task_planning_files WHERE task_id = ?", taskId).Error; err != nil {
+		return err
+	}
+	if err := t.db.Exec("DELETE FROM planning_files WHERE id IN (?)", taskPlanningFileIDs).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteProjectFiles menghapus semua project file pada task
+func (t *taskAndOwnerRepository) deleteProjectFiles(taskId uint) error {
+	var taskProjectFileIDs []uint64
+	// menggunakan Raw karena tabel task_project_files tidak memiliki model
+	if err := t.db.Raw("SELECT project_file_id FROM task_project_files WHERE task_id = ?", taskId).Scan(&taskProjectFileIDs).Error; err != nil {
+		return fmt.Errorf("failed to retrieve task project files IDs: %v", err)
+	}
+	if len(taskProjectFileIDs) == 0 {
+		return nil
+	}
+
+	if err := t.db.Exec("DELETE FROM task_project_files WHERE task_id = ?", taskId).Error; err != nil {
+		return err
+	}
+	if err := t.db.Exec("DELETE FROM project_review_files WHERE project_file_id IN (?)", taskProjectFileIDs).Error; err != nil {
+		return err
+	}
+	if err := t.db.Exec("DELETE FROM project_files WHERE id IN (?)", taskProjectFileIDs).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *taskAndOwnerRepository) DeletePlanningFile(taskID uint, fileId uint) (string, error) {
+	// file harus milik task yang diberikan
 	var planningFile domain.PlanningFile
-	if err := t.db.First(&planningFile, fileId).Error; err != nil {
+	if err := t.db.Where("id IN (?)", t.db.Session(&gorm.Session{NewDB: true}).Table("task_planning_files").Select("planning_file_id").Where("task_id = ?", taskID)).
+		First(&planningFile, fileId).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", errors.New("file not found")
+			return "", errors.New("file not found")
 		}
-		return nil, "", fmt.Errorf("failed to find file: %v", err)
+		return "", fmt.Errorf("failed to find file: %v", err)
 	}
 
 	// mengambil file name
@@ -562,23 +1202,25 @@ func (t *taskAndOwnerRepository) DeletePlanningFile(fileId uint) (*gorm.DB, stri
 
 	sqlQuery := "DELETE FROM task_planning_files WHERE planning_file_id = ?"
 	if err := t.db.Exec(sqlQuery, planningFile.ID).Error; err != nil {
-		return nil, "", err
+		return "", err
 	}
 
 	if err := t.db.Delete(&planningFile).Error; err != nil {
-		return nil, "", fmt.Errorf("failed to delete file: %v", err)
+		return "", fmt.Errorf("failed to delete file: %v", err)
 	}
 
-	return t.db, fileName, nil
+	return fileName, nil
 }
 
-func (t *taskAndOwnerRepository) DeleteProjectFile(fileId uint) (*gorm.DB, string, error) {
+func (t *taskAndOwnerRepository) DeleteProjectFile(taskID uint, fileId uint) (string, error) {
+	// file harus milik task yang diberikan
 	var projectFile domain.ProjectFile
-	if err := t.db.First(&projectFile, fileId).Error; err != nil {
+	if err := t.db.Where("id IN (?)", t.db.Session(&gorm.Session{NewDB: true}).Table("task_project_files").Select("project_file_id").Where("task_id = ?", taskID)).
+		First(&projectFile, fileId).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", errors.New("file not found")
+			return "", errors.New("file not found")
 		}
-		return nil, "", fmt.Errorf("failed to find file: %v", err)
+		return "", fmt.Errorf("failed to find file: %v", err)
 	}
 
 	var fileName string
@@ -586,189 +1228,273 @@ func (t *taskAndOwnerRepository) DeleteProjectFile(fileId uint) (*gorm.DB, strin
 
 	sqlQuery := "DELETE FROM task_project_files WHERE project_file_id = ?"
 	if err := t.db.Exec(sqlQuery, projectFile.ID).Error; err != nil {
-		return nil, "", err
+		return "", err
 	}
 
 	if err := t.db.Delete(&projectFile).Error; err != nil {
-		return nil, "", fmt.Errorf("failed to delete file: %v", err)
+		return "", fmt.Errorf("failed to delete file: %v", err)
+	}
+
+	return fileName, nil
+}
+
+// Delete mengembalikan nama file planning, project dan attachment comment dari task dan subtask-nya untuk dihapus dari S3
+func (t *taskAndOwnerRepository) Delete(taskID uint) ([]string, error) {
+	var fileNames []string
+	// semua data task dan subtask dihapus dalam satu transaksi agar tidak tersisa sebagian jika salah satu langkah gagal
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		return t.withTx(tx).deleteTask(taskID, &fileNames)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return t.db, fileName, nil
+	return fileNames, nil
 }
 
-func (t *taskAndOwnerRepository) Delete(taskID uint) (*gorm.DB, int64, int64, int64, int64, int64, error) {
+// taskFileNames mengambil nama semua file planning, project dan attachment comment pada task
+func (t *taskAndOwnerRepository) taskFileNames(taskID uint) ([]string, error) {
+	var planningFileNames, projectFileNames, attachmentFileNames []string
+	if err := t.db.Model(&domain.PlanningFile{}).
+		Where("id IN (?)", t.db.Session(&gorm.Session{NewDB: true}).Table("task_planning_files").Select("planning_file_id").Where("task_id = ?", taskID)).
+		Pluck("file_name", &planningFileNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to find planning files: %v", err)
+	}
+	if err := t.db.Model(&domain.ProjectFile{}).
+		Where("id IN (?)", t.db.Session(&gorm.Session{NewDB: true}).Table("task_project_files").Select("project_file_id").Where("task_id = ?", taskID)).
+		Pluck("file_name", &projectFileNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project files: %v", err)
+	}
+	if err := t.db.Model(&domain.CommentAttachment{}).
+		Where("comment_id IN (?)", t.db.Session(&gorm.Session{NewDB: true}).Model(&domain.Comment{}).Select("id").Where("task_id = ?", taskID)).
+		Pluck("file_name", &attachmentFileNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to find comment attachments: %v", err)
+	}
+
+	fileNames := append(planningFileNames, projectFileNames...)
+	return append(fileNames, attachmentFileNames...), nil
+}
+
+// deleteTask menghapus task beserta subtask dan semua datanya, harus dipanggil di dalam transaksi.
+// Nama file yang ikut terhapus ditambahkan ke fileNames
+func (t *taskAndOwnerRepository) deleteTask(taskID uint, fileNames *[]string) error {
 	// validasi task
 	var task domain.Task
 	if err := t.db.First(&task, taskID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, 0, 0, 0, 0, errors.New("task not found")
+			return errors.New("task not found")
 		}
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to find task: %v", err)
+		return fmt.Errorf("failed to find task: %v", err)
 	}
 
-	var (
-		countOwners       int64
-		countManager      int64
-		countEmployee     int64
-		countPlanningFile int64
-		countProjectFile  int64
-	)
+	// hapus subtask terlebih dahulu beserta member dan file-nya
+	var subtaskIDs []uint
+	if err := t.db.Model(&domain.Task{}).Where("parent_id = ?", taskID).Pluck("id", &subtaskIDs).Error; err != nil {
+		return fmt.Errorf("failed to find subtasks: %v", err)
+	}
+	for _, subtaskID := range subtaskIDs {
+		if err := t.deleteTask(subtaskID, fileNames); err != nil {
+			return err
+		}
+	}
 
-	// Validasi owners
-	var ownerIDs []uint64
-	rows, err := t.db.Table("tasks").Select("tasks.owner_id").Joins("INNER JOIN owners ON owners.id = tasks.owner_id").Where("tasks.id = ?", taskID).Rows()
+	// nama file diambil sebelum datanya dihapus
+	taskFileNames, err := t.taskFileNames(taskID)
 	if err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("gagal mengambil ID pemilik untuk task: %v", err)
+		return err
 	}
-	defer rows.Close()
+	*fileNames = append(*fileNames, taskFileNames...)
 
-	for rows.Next() {
-		var ownerID uint64
-		if err := rows.Scan(&ownerID); err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("gagal membaca ID pemilik: %v", err)
-		}
-		log.Println(ownerID)
-		ownerIDs = append(ownerIDs, ownerID)
+	// hapus semua member task (owner, manager, employee)
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.TaskMember{}).Error; err != nil {
+		return fmt.Errorf("failed to delete task members: %v", err)
 	}
 
-	if len(ownerIDs) > 0 {
-		if err := t.db.Where("owner_id IN (?)", ownerIDs).Delete(&domain.Task{}).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("gagal menghapus referensi pemilik dari tasks: %v", err)
-		}
+	if err := t.deletePlanningFiles(taskID); err != nil {
+		return err
+	}
+	if err := t.deleteProjectFiles(taskID); err != nil {
+		return err
+	}
 
-		if err := t.db.Where("id IN (?)", ownerIDs).Delete(&domain.Owner{}).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("gagal menghapus pemilik dari basis data: %v", err)
-		}
-		countOwners = 1
+	// hapus undangan yang belum diterima
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.TaskInvitation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete invitations: %v", err)
 	}
 
-	// Validasi manager
-	var taskManagerIDs []uint
-	// menggunakan taskmanagersQuery.Table untuk count, karena tidak bisa pluck manager_id, dikarenakan tidak ada model dari query ini
-	rows, err = t.db.Raw("SELECT manager_id FROM task_managers WHERE task_id = ?", taskID).Rows()
-	if err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to retrieve task manager IDs: %v", err)
+	// hapus comment beserta attachment-nya
+	commentIDs := t.db.Session(&gorm.Session{NewDB: true}).Model(&domain.Comment{}).Select("id").Where("task_id = ?", taskID)
+	if err := t.db.Where("comment_id IN (?)", commentIDs).Delete(&domain.CommentAttachment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete comment attachments: %v", err)
+	}
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.Comment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete comments: %v", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var managerID uint64
-		if err := rows.Scan(&managerID); err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to read manager ID: %v", err)
-		}
-		taskManagerIDs = append(taskManagerIDs, uint(managerID))
+	// hapus riwayat approval planning
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.PlanningApproval{}).Error; err != nil {
+		return fmt.Errorf("failed to delete planning approvals: %v", err)
 	}
 
-	if len(taskManagerIDs) > 0 {
-		taskmanagersDelete := "DELETE FROM task_managers WHERE task_id = ?"
-		if err := t.db.Exec(taskmanagersDelete, taskID).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
+	// hapus riwayat review project, file-nya sudah dihapus bersama project file task
+	if err := t.db.Exec("DELETE FROM project_review_files WHERE project_review_id IN (?)",
+		t.db.Session(&gorm.Session{NewDB: true}).Model(&domain.ProjectReview{}).Select("id").Where("task_id = ?", taskID)).Error; err != nil {
+		return fmt.Errorf("failed to delete project review files: %v", err)
+	}
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.ProjectReview{}).Error; err != nil {
+		return fmt.Errorf("failed to delete project reviews: %v", err)
+	}
 
-		managersDelete := "DELETE FROM managers WHERE id IN (?)"
-		if err := t.db.Exec(managersDelete, taskManagerIDs).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
-		countManager = 1
+	// hapus aturan pengulangan task, salinan yang sudah dibuat tetap ada
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.TaskRecurrence{}).Error; err != nil {
+		return fmt.Errorf("failed to delete recurrence: %v", err)
+	}
+	if err := t.db.Model(&domain.Task{}).Where("recurrence_source_id = ?", taskID).Update("recurrence_source_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to detach recurring tasks: %v", err)
 	}
 
-	// Validasi employee
-	var taskEmployeeIDs []uint
-	// menggunakan taskEmployeesQuery.Table untuk count, karena tidak bisa pluck employee_id, dikarenakan tidak ada model dari query ini
-	rows, err = t.db.Raw("SELECT employee_id FROM task_employees WHERE task_id = ?", taskID).Rows()
-	if err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to retrieve task employee IDs: %v", err)
+	// hapus checklist task
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.ChecklistItem{}).Error; err != nil {
+		return fmt.Errorf("failed to delete checklist: %v", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var employeeID uint64
-		if err := rows.Scan(&employeeID); err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-		}
-		taskEmployeeIDs = append(taskEmployeeIDs, uint(employeeID))
+	// hapus nilai custom field task
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.CustomFieldValue{}).Error; err != nil {
+		return fmt.Errorf("failed to delete custom field values: %v", err)
 	}
 
-	if len(taskEmployeeIDs) > 0 {
-		taskEmployeesDelete := "DELETE FROM task_employees WHERE task_id = ?"
-		if err := t.db.Exec(taskEmployeesDelete, taskID).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
+	// lepaskan semua label dari task
+	if err := t.db.Exec("DELETE FROM task_labels WHERE task_id = ?", taskID).Error; err != nil {
+		return fmt.Errorf("failed to delete task labels: %v", err)
+	}
 
-		employeesDelete := "DELETE FROM employees WHERE id IN (?)"
-		if err := t.db.Exec(employeesDelete, taskEmployeeIDs).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
-		countEmployee = 1
+	// hapus mention dan notifikasi task
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.Mention{}).Error; err != nil {
+		return fmt.Errorf("failed to delete mentions: %v", err)
+	}
+	if err := t.db.Where("task_id = ?", taskID).Delete(&domain.Notification{}).Error; err != nil {
+		return fmt.Errorf("failed to delete notifications: %v", err)
 	}
 
-	// validasi PlanningFile
-	var taskPlanningFileIDs []uint
-	// Eksekusi query untuk mengambil planning_file_id
-	rows, err = t.db.Raw("SELECT planning_file_id FROM task_planning_files WHERE task_id = ?", taskID).Rows()
-	if err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to retrieve task planning files IDs: %v", err)
+	// hapus dependency dimana task ini menunggu atau ditunggu task lain
+	if err := t.db.Where("task_id = ? OR blocked_by_id = ?", taskID, taskID).Delete(&domain.TaskDependency{}).Error; err != nil {
+		return fmt.Errorf("failed to delete dependencies: %v", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var planningFileID uint64
-		if err := rows.Scan(&planningFileID); err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-		}
-		taskPlanningFileIDs = append(taskPlanningFileIDs, uint(planningFileID))
+	// hapus entri dari task berdasarkan id yang ditemukan
+	if err := t.db.Delete(&task).Error; err != nil {
+		return fmt.Errorf("failed to delete tasks: %v", err)
 	}
 
-	if len(taskPlanningFileIDs) > 0 {
-		taskPlanningFileDelete := "DELETE FROM task_planning_files WHERE task_id = ?"
-		if err := t.db.Exec(taskPlanningFileDelete, taskID).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
+	return nil
+}
 
-		planningFileDelete := "DELETE FROM planning_files WHERE id IN (?)"
-		if err := t.db.Exec(planningFileDelete, taskPlanningFileIDs).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
-		countPlanningFile = 1
+func (t *taskAndOwnerRepository) CreateInvitation(invitation *domain.TaskInvitation) (*domain.TaskInvitation, error) {
+	// validasi agar email yang sama tidak diundang dua kali pada task yang sama
+	var count int64
+	if err := t.db.Model(&domain.TaskInvitation{}).
+		Where("task_id = ? AND email = ? AND accepted_at IS NULL", invitation.TaskID, invitation.Email).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Email has already been invited to this task")
 	}
 
-	// validasi ProjectFile
-	var taskProjectFileIDs []uint
-	// Eksekusi query untuk mengambil project_file_id
-	rows, err = t.db.Raw("SELECT project_file_id FROM task_project_files WHERE task_id = ?", taskID).Rows()
-	if err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to retrieve task project files IDs: %v", err)
+	if err := t.db.Create(invitation).Error; err != nil {
+		return nil, fmt.Errorf("Failed to save invitation: %v", err)
 	}
-	defer rows.Close() // Pastikan rows ditutup dengan benar
+	return invitation, nil
+}
 
-	// looping hasil query dalam rows yang datanya adalah nilai actual dari project_file_id, kemdian simpan ke dalam projectFileID
-	for rows.Next() {
-		var projectFileID uint64
-		if err := rows.Scan(&projectFileID); err != nil {
-			return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to read project file ID: %v", err)
-		}
-		taskProjectFileIDs = append(taskProjectFileIDs, uint(projectFileID))
+func (t *taskAndOwnerRepository) FindAllInvitations(taskID uint) ([]*domain.TaskInvitation, error) {
+	var invitations []*domain.TaskInvitation
+	if err := t.db.Where("task_id = ? AND accepted_at IS NULL", taskID).Find(&invitations).Error; err != nil {
+		return nil, errors.New("Failed to find invitations")
 	}
+	return invitations, nil
+}
 
-	if len(taskProjectFileIDs) > 0 {
-		// Hapus task_project_files jika ada
-		taskProjectFileDelete := "DELETE FROM task_project_files WHERE task_id = ?"
-		if err := t.db.Exec(taskProjectFileDelete, taskID).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
+func (t *taskAndOwnerRepository) DeleteInvitation(taskID uint, invitationID uint) error {
+	result := t.db.Where("id = ? AND task_id = ? AND accepted_at IS NULL", invitationID, taskID).Delete(&domain.TaskInvitation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete invitation: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invitation not found")
+	}
+	return nil
+}
 
-		// Hapus project_files menggunakan klausa IN
-		projectFileDelete := "DELETE FROM project_files WHERE id IN (?)"
-		if err := t.db.Exec(projectFileDelete, taskProjectFileIDs).Error; err != nil {
-			return nil, 0, 0, 0, 0, 0, err
-		}
-		countProjectFile = 1
+func (t *taskAndOwnerRepository) AcceptInvitations(user *domain.User) ([]*domain.TaskInvitation, error) {
+	var invitations []*domain.TaskInvitation
+	if err := t.db.Where("email = ? AND accepted_at IS NULL", user.Email).Find(&invitations).Error; err != nil {
+		return nil, err
 	}
 
-	// hapus entri dari task berdasarkan id yang ditemukan
-	if err := t.db.Delete(&task).Error; err != nil {
-		return nil, 0, 0, 0, 0, 0, fmt.Errorf("failed to delete tasks: %v", err)
+	var accepted []*domain.TaskInvitation
+	for _, invitation := range invitations {
+		err := t.db.Transaction(func(tx *gorm.DB) error {
+			var task domain.Task
+			if err := tx.First(&task, invitation.TaskID).Error; err != nil {
+				// task sudah dihapus, undangan tidak berlaku lagi
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return tx.Delete(invitation).Error
+				}
+				return err
+			}
+
+			// user yang diundang otomatis menjadi member workspace dari task
+			var countWorkspaceMember int64
+			if err := tx.Model(&domain.WorkspaceMember{}).
+				Where("workspace_id = ? AND user_id = ?", task.WorkspaceID, user.ID).
+				Count(&countWorkspaceMember).Error; err != nil {
+				return err
+			}
+			if countWorkspaceMember == 0 {
+				workspaceMember := domain.WorkspaceMember{
+					WorkspaceID: task.WorkspaceID,
+					UserID:      user.ID,
+					Email:       user.Email,
+					Role:        domain.WorkspaceRoleMember,
+				}
+				if err := tx.Create(&workspaceMember).Error; err != nil {
+					return err
+				}
+			}
+
+			// user hanya bisa memiliki satu role pada task, jika sudah menjadi member undangan dianggap selesai
+			var countMember int64
+			if err := tx.Model(&domain.TaskMember{}).
+				Where("task_id = ? AND user_id = ?", task.ID, user.ID).
+				Count(&countMember).Error; err != nil {
+				return err
+			}
+			if countMember == 0 {
+				member := domain.TaskMember{
+					TaskID: task.ID,
+					UserID: user.ID,
+					Email:  user.Email,
+					Role:   invitation.Role,
+				}
+				if err := tx.Create(&member).Error; err != nil {
+					return err
+				}
+			}
+
+			now := time.Now()
+			invitation.AcceptedAt = &now
+			if err := tx.Model(invitation).Update("accepted_at", now).Error; err != nil {
+				return err
+			}
+			accepted = append(accepted, invitation)
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to accept invitation: %v", err)
+		}
 	}
 
-	return t.db, countOwners, countManager, countEmployee, countPlanningFile, countProjectFile, nil
+	return accepted, nil
 }
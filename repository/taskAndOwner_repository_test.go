@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+// blockersFrom membuat fungsi pencarian blocker dari graph dependency, key adalah task dan value adalah task blocker-nya
+func blockersFrom(graph map[uint64][]uint64, calls *int) func(taskIDs []uint64) ([]uint64, error) {
+	return func(taskIDs []uint64) ([]uint64, error) {
+		*calls++
+		var blockers []uint64
+		for _, id := range taskIDs {
+			blockers = append(blockers, graph[id]...)
+		}
+		return blockers, nil
+	}
+}
+
+func TestDependencyCreatesCycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		graph       map[uint64][]uint64
+		taskID      uint64
+		blockedByID uint64
+		want        bool
+	}{
+		{"no existing dependencies", map[uint64][]uint64{}, 1, 2, false},
+		{"task depends on itself", map[uint64][]uint64{}, 1, 1, true},
+		{"direct cycle", map[uint64][]uint64{2: {1}}, 1, 2, true},
+		{"indirect cycle", map[uint64][]uint64{2: {3}, 3: {4}, 4: {1}}, 1, 2, true},
+		{"chain without cycle", map[uint64][]uint64{2: {3}, 3: {4}}, 1, 2, false},
+		{"closing an existing chain", map[uint64][]uint64{1: {2}, 2: {3}}, 3, 1, true},
+		{"diamond without cycle", map[uint64][]uint64{2: {3, 4}, 3: {5}, 4: {5}}, 1, 2, false},
+		{"diamond with cycle", map[uint64][]uint64{2: {3, 4}, 3: {5}, 4: {5}, 5: {1}}, 1, 2, true},
+		{"existing cycle not involving task", map[uint64][]uint64{2: {3}, 3: {2}}, 1, 2, false},
+		{"task already blocks others", map[uint64][]uint64{5: {1}, 2: {6}}, 1, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got, err := dependencyCreatesCycle(tt.taskID, tt.blockedByID, blockersFrom(tt.graph, &calls))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected cycle %v, got %v", tt.want, got)
+			}
+			// setiap task hanya dikunjungi sekali sehingga jumlah query tidak melebihi jumlah task
+			if calls > len(tt.graph)+1 {
+				t.Fatalf("expected at most %d lookups, got %d", len(tt.graph)+1, calls)
+			}
+		})
+	}
+}
+
+func TestDependencyCreatesCycleReturnsLookupError(t *testing.T) {
+	lookupErr := errors.New("connection lost")
+	_, err := dependencyCreatesCycle(1, 2, func(taskIDs []uint64) ([]uint64, error) {
+		return nil, lookupErr
+	})
+	if !errors.Is(err, lookupErr) {
+		t.Fatalf("expected lookup error, got %v", err)
+	}
+}
@@ -1,8 +1,8 @@
 package repository
 
 import (
-	"gorm.io/gorm"
 	"manajemen_tugas_master/model/domain"
+	"time"
 )
 
 // UserRepository adalah interface untuk operasi-operasi yang berhubungan dengan entitas User
@@ -10,7 +10,21 @@ type UserRepository interface {
 	Signup(user *domain.User) (*domain.User, error)
 	Login(user *domain.User) (*domain.User, error)
 	FindById(id interface{}) (*domain.User, error)
+	FindByEmail(email string) (*domain.User, error)
 	FindAll() ([]*domain.User, error)
 	Update(user *domain.User) (*domain.User, error)
-	Delete(id uint) (*gorm.DB, error)
+	Delete(id uint) error
+	CreateRefreshToken(refreshToken *domain.RefreshToken) (*domain.RefreshToken, error)
+	FindRefreshToken(tokenHash string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(id uint64) (bool, error)
+	RevokeAllRefreshTokens(userID uint64) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	CreatePasswordReset(passwordReset *domain.PasswordReset) (*domain.PasswordReset, error)
+	FindPasswordReset(tokenHash string) (*domain.PasswordReset, error)
+	ResetPassword(passwordReset *domain.PasswordReset, hashedPassword string) error
+	CreateEmailVerification(emailVerification *domain.EmailVerification) (*domain.EmailVerification, error)
+	FindEmailVerification(tokenHash string) (*domain.EmailVerification, error)
+	VerifyEmail(emailVerification *domain.EmailVerification) (*domain.User, error)
+	DeleteUnusedEmailVerifications(userID uint64) error
 }
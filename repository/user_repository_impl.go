@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"gorm.io/gorm"
 	"manajemen_tugas_master/model/domain"
+	"time"
 )
 
 // userRepository adalah implementasi dari UserRepository
@@ -40,6 +41,17 @@ func (r *userRepository) FindById(id interface{}) (*domain.User, error) {
 	return user, nil
 }
 
+func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, "email = ?", email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("User not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) FindAll() ([]*domain.User, error) {
 	var users []*domain.User
 	if err := r.db.Find(&users).Error; err != nil {
@@ -55,18 +67,249 @@ func (r *userRepository) Update(user *domain.User) (*domain.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) Delete(id uint) (*gorm.DB, error) {
+func (r *userRepository) Delete(id uint) error {
 	var user *domain.User
 	if err := r.db.First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to find user: %v", err)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// kepemilikan task harus dipindahkan terlebih dahulu agar task tidak kehilangan owner
+		var ownedTasks int64
+		if err := tx.Model(&domain.TaskMember{}).Where("user_id = ? AND role = ?", user.ID, domain.TaskRoleOwner).Count(&ownedTasks).Error; err != nil {
+			return err
+		}
+		if ownedTasks > 0 {
+			return errors.New("User still owns tasks, transfer the ownership before deleting the user")
+		}
+
+		// workspace tidak boleh ditinggalkan tanpa admin
+		var soleAdmin int64
+		otherAdmins := tx.Session(&gorm.Session{NewDB: true}).Table("workspace_members AS others").Select("1").
+			Where("others.workspace_id = workspace_members.workspace_id AND others.role = ? AND others.user_id <> ?", domain.WorkspaceRoleAdmin, user.ID)
+		if err := tx.Model(&domain.WorkspaceMember{}).
+			Where("user_id = ? AND role = ? AND NOT EXISTS (?)", user.ID, domain.WorkspaceRoleAdmin, otherAdmins).
+			Count(&soleAdmin).Error; err != nil {
+			return err
+		}
+		if soleAdmin > 0 {
+			return errors.New("User is the only admin of a workspace, add another admin before deleting the user")
+		}
+
+		// data milik user ikut dihapus
+		ownedData := []struct {
+			model  interface{}
+			column string
+		}{
+			{&domain.RefreshToken{}, "user_id"},
+			{&domain.EmailVerification{}, "user_id"},
+			{&domain.PasswordReset{}, "user_id"},
+			{&domain.Notification{}, "user_id"},
+			{&domain.Mention{}, "mentioned_user_id"},
+			{&domain.TaskMember{}, "user_id"},
+			{&domain.WorkspaceMember{}, "user_id"},
+		}
+		for _, data := range ownedData {
+			if err := tx.Where(data.column+" = ?", user.ID).Delete(data.model).Error; err != nil {
+				return fmt.Errorf("failed to delete user data: %v", err)
+			}
+		}
+
+		// riwayat task tetap disimpan, referensi ke user dikosongkan
+		references := []struct {
+			model  interface{}
+			column string
+		}{
+			{&domain.Comment{}, "author_id"},
+			{&domain.TaskActivity{}, "actor_id"},
+			{&domain.ChecklistItem{}, "assignee_id"},
+			{&domain.PlanningApproval{}, "submitted_by_id"},
+			{&domain.PlanningApproval{}, "decided_by_id"},
+			{&domain.ProjectReview{}, "submitted_by_id"},
+			{&domain.ProjectReview{}, "reviewed_by_id"},
+		}
+		for _, reference := range references {
+			if err := tx.Model(reference.model).Where(reference.column+" = ?", user.ID).Update(reference.column, nil).Error; err != nil {
+				return fmt.Errorf("failed to detach user history: %v", err)
+			}
+		}
+
+		if err := tx.Delete(&user).Error; err != nil {
+			return fmt.Errorf("failed to delete user: %v", err)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) CreateRefreshToken(refreshToken *domain.RefreshToken) (*domain.RefreshToken, error) {
+	if err := r.db.Create(refreshToken).Error; err != nil {
+		return nil, fmt.Errorf("Failed to save refresh token: %v", err)
+	}
+	return refreshToken, nil
+}
+
+func (r *userRepository) FindRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+	var refreshToken domain.RefreshToken
+	if err := r.db.First(&refreshToken, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Refresh token not found")
 		}
-		return nil, fmt.Errorf("failed to find user: %v", err)
+		return nil, err
 	}
+	return &refreshToken, nil
+}
 
-	if err := r.db.Delete(&user).Error; err != nil {
-		return nil, fmt.Errorf("failed to delete related users, because they are associated with tasks")
+// RevokeRefreshToken mengembalikan false jika token sudah dicabut sebelumnya, misalnya oleh request lain yang bersamaan
+func (r *userRepository) RevokeRefreshToken(id uint64) (bool, error) {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
 	}
+	return result.RowsAffected > 0, nil
+}
 
-	return r.db, nil
+func (r *userRepository) RevokeAllRefreshTokens(userID uint64) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *userRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	// bersihkan token yang sudah kadaluarsa, karena token tersebut sudah pasti ditolak oleh jwt
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	revokedToken := domain.RevokedToken{Jti: jti, ExpiresAt: expiresAt}
+	if err := r.db.Where(domain.RevokedToken{Jti: jti}).FirstOrCreate(&revokedToken).Error; err != nil {
+		return fmt.Errorf("Failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+func (r *userRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *userRepository) CreatePasswordReset(passwordReset *domain.PasswordReset) (*domain.PasswordReset, error) {
+	if err := r.db.Create(passwordReset).Error; err != nil {
+		return nil, fmt.Errorf("Failed to save password reset token: %v", err)
+	}
+	return passwordReset, nil
+}
+
+func (r *userRepository) FindPasswordReset(tokenHash string) (*domain.PasswordReset, error) {
+	var passwordReset domain.PasswordReset
+	if err := r.db.First(&passwordReset, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Password reset token not found")
+		}
+		return nil, err
+	}
+	return &passwordReset, nil
+}
+
+func (r *userRepository) ResetPassword(passwordReset *domain.PasswordReset, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// token hanya bisa dipakai sekali, update dengan kondisi used_at IS NULL agar aman dari request bersamaan
+		result := tx.Model(&domain.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", passwordReset.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Password reset token has already been used")
+		}
+
+		if err := tx.Model(&domain.User{}).Where("id = ?", passwordReset.UserID).Update("password", hashedPassword).Error; err != nil {
+			return fmt.Errorf("Failed to update password: %v", err)
+		}
+
+		// token reset lain milik user yang belum dipakai ikut dinonaktifkan
+		if err := tx.Model(&domain.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", passwordReset.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		// semua sesi yang sedang login harus login ulang dengan password baru
+		if err := tx.Model(&domain.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", passwordReset.UserID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *userRepository) CreateEmailVerification(emailVerification *domain.EmailVerification) (*domain.EmailVerification, error) {
+	if err := r.db.Create(emailVerification).Error; err != nil {
+		return nil, fmt.Errorf("Failed to save email verification token: %v", err)
+	}
+	return emailVerification, nil
+}
+
+func (r *userRepository) FindEmailVerification(tokenHash string) (*domain.EmailVerification, error) {
+	var emailVerification domain.EmailVerification
+	if err := r.db.First(&emailVerification, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Email verification token not found")
+		}
+		return nil, err
+	}
+	return &emailVerification, nil
+}
+
+func (r *userRepository) VerifyEmail(emailVerification *domain.EmailVerification) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		result := tx.Model(&domain.EmailVerification{}).
+			Where("id = ? AND used_at IS NULL", emailVerification.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Email verification token has already been used")
+		}
+
+		// token hanya berlaku untuk email saat token dikirim, jika email sudah diganti token ditolak
+		result = tx.Model(&domain.User{}).Where("id = ? AND email = ?", emailVerification.UserID, emailVerification.Email).
+			Updates(map[string]interface{}{"verified": true, "verified_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("Failed to verify user: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Invalid verification token")
+		}
+
+		return tx.First(&user, emailVerification.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUnusedEmailVerifications menghapus token verifikasi yang belum dipakai, dipanggil saat email user diganti
+func (r *userRepository) DeleteUnusedEmailVerifications(userID uint64) error {
+	if err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Delete(&domain.EmailVerification{}).Error; err != nil {
+		return fmt.Errorf("Failed to delete email verification tokens: %v", err)
+	}
+	return nil
 }
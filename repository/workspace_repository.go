@@ -0,0 +1,30 @@
+package repository
+
+import "manajemen_tugas_master/model/domain"
+
+type WorkspaceRepository interface {
+	Create(user *domain.User, workspace *domain.Workspace) (*domain.Workspace, error)
+	FindById(id uint) (*domain.Workspace, error)
+	FindAllByUser(userID uint) ([]*domain.Workspace, error)
+	Update(workspace *domain.Workspace) (*domain.Workspace, error)
+	FindMember(workspaceID uint, userID uint) (*domain.WorkspaceMember, error)
+	AddMember(member *domain.WorkspaceMember) (*domain.WorkspaceMember, error)
+	DeleteMember(workspaceID uint, userID uint) error
+	CreateLabel(label *domain.Label) (*domain.Label, error)
+	FindAllLabels(workspaceID uint) ([]*domain.Label, error)
+	FindLabel(workspaceID uint, labelID uint) (*domain.Label, error)
+	UpdateLabel(label *domain.Label) (*domain.Label, error)
+	DeleteLabel(label *domain.Label) error
+	CreateCustomField(field *domain.CustomField) (*domain.CustomField, error)
+	FindAllCustomFields(workspaceID uint) ([]*domain.CustomField, error)
+	FindCustomField(workspaceID uint, fieldID uint) (*domain.CustomField, error)
+	FindCustomFieldByUser(userID uint, fieldID uint) (*domain.CustomField, error)
+	UpdateCustomField(field *domain.CustomField) (*domain.CustomField, error)
+	DeleteCustomField(field *domain.CustomField) error
+	CreateTaskTemplate(template *domain.TaskTemplate) (*domain.TaskTemplate, error)
+	FindAllTaskTemplates(workspaceID uint) ([]*domain.TaskTemplate, error)
+	FindTaskTemplate(workspaceID uint, templateID uint) (*domain.TaskTemplate, error)
+	FindTaskTemplateById(templateID uint) (*domain.TaskTemplate, error)
+	UpdateTaskTemplate(template *domain.TaskTemplate) (*domain.TaskTemplate, error)
+	DeleteTaskTemplate(template *domain.TaskTemplate) error
+}
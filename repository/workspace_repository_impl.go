@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+
+	"gorm.io/gorm"
+)
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db}
+}
+
+func (w *workspaceRepository) Create(user *domain.User, workspace *domain.Workspace) (*domain.Workspace, error) {
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		// pembuat workspace otomatis menjadi admin workspace
+		member := domain.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      user.ID,
+			Email:       user.Email,
+			Role:        domain.WorkspaceRoleAdmin,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		workspace.Members = []domain.WorkspaceMember{member}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create workspace: %v", err)
+	}
+
+	return workspace, nil
+}
+
+func (w *workspaceRepository) FindById(id uint) (*domain.Workspace, error) {
+	var workspace domain.Workspace
+	if err := w.db.Preload("Members").First(&workspace, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Workspace not found")
+		}
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (w *workspaceRepository) FindAllByUser(userID uint) ([]*domain.Workspace, error) {
+	var workspaces []*domain.Workspace
+	if err := w.db.
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Find(&workspaces).Error; err != nil {
+		return nil, errors.New("Failed to find workspaces")
+	}
+	return workspaces, nil
+}
+
+func (w *workspaceRepository) Update(workspace *domain.Workspace) (*domain.Workspace, error) {
+	if err := w.db.Model(workspace).Updates(domain.Workspace{Name: workspace.Name}).Error; err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (w *workspaceRepository) FindMember(workspaceID uint, userID uint) (*domain.WorkspaceMember, error) {
+	var member domain.WorkspaceMember
+	if err := w.db.First(&member, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Only for workspace member")
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (w *workspaceRepository) AddMember(member *domain.WorkspaceMember) (*domain.WorkspaceMember, error) {
+	var count int64
+	if err := w.db.Model(&domain.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("User is already a member of this workspace")
+	}
+
+	if err := w.db.Create(member).Error; err != nil {
+		return nil, fmt.Errorf("Failed to add member: %v", err)
+	}
+	return member, nil
+}
+
+func (w *workspaceRepository) DeleteMember(workspaceID uint, userID uint) error {
+	member, err := w.FindMember(workspaceID, userID)
+	if err != nil {
+		return errors.New("Member not found")
+	}
+
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		// workspace harus selalu memiliki minimal satu admin
+		if member.Role == domain.WorkspaceRoleAdmin {
+			var countAdmin int64
+			if err := tx.Model(&domain.WorkspaceMember{}).
+				Where("workspace_id = ? AND role = ?", workspaceID, domain.WorkspaceRoleAdmin).
+				Count(&countAdmin).Error; err != nil {
+				return err
+			}
+			if countAdmin <= 1 {
+				return errors.New("Workspace must have at least one admin")
+			}
+		}
+
+		// kepemilikan task di workspace harus dipindahkan terlebih dahulu agar task tidak kehilangan owner
+		workspaceTaskIDs := tx.Session(&gorm.Session{NewDB: true}).Model(&domain.Task{}).Select("id").Where("workspace_id = ?", workspaceID)
+		var ownedTasks int64
+		if err := tx.Model(&domain.TaskMember{}).
+			Where("user_id = ? AND role = ? AND task_id IN (?)", userID, domain.TaskRoleOwner, workspaceTaskIDs).
+			Count(&ownedTasks).Error; err != nil {
+			return err
+		}
+		if ownedTasks > 0 {
+			return errors.New("Member still owns tasks in this workspace, transfer the ownership before removing the member")
+		}
+
+		// member yang keluar tidak lagi menjadi manager atau employee pada task di workspace
+		if err := tx.Where("user_id = ? AND task_id IN (?)", userID, workspaceTaskIDs).Delete(&domain.TaskMember{}).Error; err != nil {
+			return fmt.Errorf("Failed to delete task members: %v", err)
+		}
+
+		if err := tx.Delete(member).Error; err != nil {
+			return fmt.Errorf("Failed to delete member: %v", err)
+		}
+		return nil
+	})
+}
+
+func (w *workspaceRepository) CreateLabel(label *domain.Label) (*domain.Label, error) {
+	var count int64
+	if err := w.db.Model(&domain.Label{}).
+		Where("workspace_id = ? AND name = ?", label.WorkspaceID, label.Name).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Label already exist")
+	}
+
+	if err := w.db.Create(label).Error; err != nil {
+		return nil, fmt.Errorf("Failed to create label: %v", err)
+	}
+	return label, nil
+}
+
+func (w *workspaceRepository) FindAllLabels(workspaceID uint) ([]*domain.Label, error) {
+	var labels []*domain.Label
+	if err := w.db.Where("workspace_id = ?", workspaceID).Order("name ASC").Find(&labels).Error; err != nil {
+		return nil, errors.New("Failed to find labels")
+	}
+	return labels, nil
+}
+
+func (w *workspaceRepository) FindLabel(workspaceID uint, labelID uint) (*domain.Label, error) {
+	var label domain.Label
+	if err := w.db.First(&label, "id = ? AND workspace_id = ?", labelID, workspaceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Label not found")
+		}
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (w *workspaceRepository) UpdateLabel(label *domain.Label) (*domain.Label, error) {
+	var count int64
+	if err := w.db.Model(&domain.Label{}).
+		Where("workspace_id = ? AND name = ? AND id <> ?", label.WorkspaceID, label.Name, label.ID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Label already exist")
+	}
+
+	if err := w.db.Model(label).Updates(domain.Label{Name: label.Name, Color: label.Color}).Error; err != nil {
+		return nil, fmt.Errorf("Failed to update label: %v", err)
+	}
+	return label, nil
+}
+
+func (w *workspaceRepository) DeleteLabel(label *domain.Label) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		// lepaskan label dari semua task sebelum label dihapus
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(label).Error
+	})
+}
+
+func (w *workspaceRepository) CreateCustomField(field *domain.CustomField) (*domain.CustomField, error) {
+	var count int64
+	if err := w.db.Model(&domain.CustomField{}).
+		Where("workspace_id = ? AND name = ?", field.WorkspaceID, field.Name).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Custom field already exist")
+	}
+
+	if err := w.db.Create(field).Error; err != nil {
+		return nil, fmt.Errorf("Failed to create custom field: %v", err)
+	}
+	return field, nil
+}
+
+func (w *workspaceRepository) FindAllCustomFields(workspaceID uint) ([]*domain.CustomField, error) {
+	var fields []*domain.CustomField
+	if err := w.db.Where("workspace_id = ?", workspaceID).Order("name ASC").Find(&fields).Error; err != nil {
+		return nil, errors.New("Failed to find custom fields")
+	}
+	return fields, nil
+}
+
+func (w *workspaceRepository) FindCustomField(workspaceID uint, fieldID uint) (*domain.CustomField, error) {
+	var field domain.CustomField
+	if err := w.db.First(&field, "id = ? AND workspace_id = ?", fieldID, workspaceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Custom field not found")
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+// FindCustomFieldByUser mencari custom field dari workspace yang diikuti user, custom field workspace lain dianggap tidak ada
+func (w *workspaceRepository) FindCustomFieldByUser(userID uint, fieldID uint) (*domain.CustomField, error) {
+	workspaceIDs := w.db.Session(&gorm.Session{NewDB: true}).Model(&domain.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)
+
+	var field domain.CustomField
+	if err := w.db.Where("workspace_id IN (?)", workspaceIDs).First(&field, fieldID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Custom field not found")
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (w *workspaceRepository) UpdateCustomField(field *domain.CustomField) (*domain.CustomField, error) {
+	var count int64
+	if err := w.db.Model(&domain.CustomField{}).
+		Where("workspace_id = ? AND name = ? AND id <> ?", field.WorkspaceID, field.Name, field.ID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Custom field already exist")
+	}
+
+	// tipe custom field tidak bisa diubah agar nilai yang sudah tersimpan tetap valid
+	if err := w.db.Model(field).Select("name", "options").Updates(field).Error; err != nil {
+		return nil, fmt.Errorf("Failed to update custom field: %v", err)
+	}
+	return field, nil
+}
+
+func (w *workspaceRepository) DeleteCustomField(field *domain.CustomField) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		// nilai custom field pada semua task ikut dihapus
+		if err := tx.Where("custom_field_id = ?", field.ID).Delete(&domain.CustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(field).Error
+	})
+}
+
+func (w *workspaceRepository) CreateTaskTemplate(template *domain.TaskTemplate) (*domain.TaskTemplate, error) {
+	var count int64
+	if err := w.db.Model(&domain.TaskTemplate{}).
+		Where("workspace_id = ? AND name = ?", template.WorkspaceID, template.Name).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Template already exist")
+	}
+
+	if err := w.db.Create(template).Error; err != nil {
+		return nil, fmt.Errorf("Failed to create template: %v", err)
+	}
+	return template, nil
+}
+
+func (w *workspaceRepository) FindAllTaskTemplates(workspaceID uint) ([]*domain.TaskTemplate, error) {
+	var templates []*domain.TaskTemplate
+	if err := w.db.Where("workspace_id = ?", workspaceID).Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, errors.New("Failed to find templates")
+	}
+	return templates, nil
+}
+
+func (w *workspaceRepository) FindTaskTemplate(workspaceID uint, templateID uint) (*domain.TaskTemplate, error) {
+	var template domain.TaskTemplate
+	if err := w.db.First(&template, "id = ? AND workspace_id = ?", templateID, workspaceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Template not found")
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (w *workspaceRepository) FindTaskTemplateById(templateID uint) (*domain.TaskTemplate, error) {
+	var template domain.TaskTemplate
+	if err := w.db.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Template not found")
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (w *workspaceRepository) UpdateTaskTemplate(template *domain.TaskTemplate) (*domain.TaskTemplate, error) {
+	var count int64
+	if err := w.db.Model(&domain.TaskTemplate{}).
+		Where("workspace_id = ? AND name = ? AND id <> ?", template.WorkspaceID, template.Name, template.ID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("Template already exist")
+	}
+
+	// kolom dipilih secara eksplisit agar nilai kosong dan offset nil ikut tersimpan
+	if err := w.db.Model(template).Select("name", "name_task", "planning_description", "priority", "managers", "employees", "checklist", "planning_due_offset_days", "project_due_offset_days").Updates(template).Error; err != nil {
+		return nil, fmt.Errorf("Failed to update template: %v", err)
+	}
+	return template, nil
+}
+
+func (w *workspaceRepository) DeleteTaskTemplate(template *domain.TaskTemplate) error {
+	return w.db.Delete(template).Error
+}
@@ -0,0 +1,10 @@
+package service
+
+import "manajemen_tugas_master/model/domain"
+
+type CommentService interface {
+	CreateComment(taskID uint, userID uint, comment *domain.Comment) (*domain.Comment, error)
+	FindAllComments(taskID uint, userID uint) ([]*domain.Comment, error)
+	UpdateComment(taskID uint, commentID uint, userID uint, body string, attachments []domain.CommentAttachment) (*domain.Comment, error)
+	DeleteComment(taskID uint, commentID uint, userID uint) error
+}
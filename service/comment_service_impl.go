@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"manajemen_tugas_master/helper"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type commentService struct {
+	commentRepository      repository.CommentRepository
+	taskAndOwnerRepository repository.TaskAndOwnerRepository
+	notificationService    NotificationService
+	validator              *validator.Validate
+}
+
+func NewCommentService(commentRepository repository.CommentRepository, taskAndOwnerRepository repository.TaskAndOwnerRepository, notificationService NotificationService, validator *validator.Validate) CommentService {
+	return &commentService{commentRepository, taskAndOwnerRepository, notificationService, validator}
+}
+
+// processMentions mengirim notifikasi ke member task yang di-mention pada comment, kegagalan hanya dicatat karena comment sudah tersimpan
+func (c *commentService) processMentions(comment *domain.Comment, userID uint) {
+	task, err := c.taskAndOwnerRepository.FindById(uint(comment.TaskID), userID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if _, err := c.notificationService.ProcessMentions(task, uint64(userID), &comment.ID, domain.MentionSourceComment, comment.Body); err != nil {
+		log.Println(err)
+	}
+}
+
+func (c *commentService) CreateComment(taskID uint, userID uint, comment *domain.Comment) (*domain.Comment, error) {
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" && len(comment.Attachments) == 0 {
+		return nil, errors.New("Comment body or attachment is required")
+	}
+
+	// hanya owner, manager dan employee task yang bisa menulis comment
+	if err := c.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
+		return nil, err
+	}
+
+	// balasan dari sebuah balasan tetap ditempatkan pada comment utama agar thread hanya satu tingkat
+	if comment.ParentID != nil {
+		parent, err := c.commentRepository.FindById(taskID, uint(*comment.ParentID))
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	comment.TaskID = uint64(taskID)
+	authorID := uint64(userID)
+	comment.AuthorID = &authorID
+
+	commentDB, err := c.commentRepository.Create(comment)
+	if err != nil {
+		return nil, err
+	}
+	c.processMentions(commentDB, userID)
+
+	return commentDB, nil
+}
+
+func (c *commentService) FindAllComments(taskID uint, userID uint) ([]*domain.Comment, error) {
+	// comment bisa dibaca oleh semua user yang bisa melihat task
+	if _, err := c.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return c.commentRepository.FindAllByTask(taskID)
+}
+
+func (c *commentService) UpdateComment(taskID uint, commentID uint, userID uint, body string, attachments []domain.CommentAttachment) (*domain.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" && len(attachments) == 0 {
+		return nil, errors.New("Comment body or attachment is required")
+	}
+
+	if err := c.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
+		return nil, err
+	}
+
+	comment, err := c.commentRepository.FindById(taskID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// comment hanya bisa diubah oleh penulisnya
+	if !comment.IsAuthor(userID) {
+		return nil, errors.New("Only the author can edit this comment")
+	}
+
+	if body != "" {
+		comment.Body = body
+	}
+	now := time.Now()
+	comment.EditedAt = &now
+	comment.Attachments = append(comment.Attachments, attachments...)
+
+	commentDB, err := c.commentRepository.Update(comment)
+	if err != nil {
+		return nil, err
+	}
+	// hanya user yang baru di-mention pada hasil edit yang dikirimi notifikasi
+	c.processMentions(commentDB, userID)
+
+	return commentDB, nil
+}
+
+func (c *commentService) DeleteComment(taskID uint, commentID uint, userID uint) error {
+	comment, err := c.commentRepository.FindById(taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	// comment bisa dihapus oleh penulisnya, owner atau manager task
+	if !comment.IsAuthor(userID) {
+		if err := c.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+			return errors.New("Only the author, owner or manager can delete this comment")
+		}
+	} else if err := c.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
+		return err
+	}
+
+	attachments, err := c.commentRepository.Delete(comment)
+	if err != nil {
+		return err
+	}
+
+	// file attachment di S3 ikut dihapus, kegagalan hanya dicatat karena data comment sudah terhapus
+	for _, attachment := range attachments {
+		if err := helper.SetupS3Delete(attachment.FileName); err != nil {
+			log.Printf("Failed to delete comment attachment %s: %v", attachment.FileName, err)
+		}
+	}
+
+	return nil
+}
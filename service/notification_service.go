@@ -0,0 +1,11 @@
+package service
+
+import "manajemen_tugas_master/model/domain"
+
+type NotificationService interface {
+	Notify(userID uint64, taskID uint64, notificationType string, subject string, message string) error
+	ProcessMentions(task *domain.Task, authorID uint64, commentID *uint64, source string, text string) ([]*domain.Mention, error)
+	FindAllNotifications(userID uint, unreadOnly bool) ([]*domain.Notification, error)
+	MarkRead(userID uint, notificationID uint) error
+	MarkAllRead(userID uint) error
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"manajemen_tugas_master/helper"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/repository"
+	"os"
+	"strings"
+)
+
+type notificationService struct {
+	notificationRepository repository.NotificationRepository
+	userRepository         repository.UserRepository
+}
+
+func NewNotificationService(notificationRepository repository.NotificationRepository, userRepository repository.UserRepository) NotificationService {
+	return &notificationService{notificationRepository, userRepository}
+}
+
+// Notify menyimpan notifikasi in-app dan mengirim email ke user, kegagalan email hanya dicatat
+func (n *notificationService) Notify(userID uint64, taskID uint64, notificationType string, subject string, message string) error {
+	if _, err := n.notificationRepository.Create(&domain.Notification{
+		UserID:  userID,
+		TaskID:  taskID,
+		Type:    notificationType,
+		Message: message,
+	}); err != nil {
+		return err
+	}
+
+	user, err := n.userRepository.FindById(userID)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	bodyText := fmt.Sprintf("%s\n\nOpen the task:\n%s/task/%d", message, os.Getenv("APP_URL"), taskID)
+	if err := helper.SetupSES(user.Email, subject, bodyText); err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// ProcessMentions menyimpan mention yang ditujukan ke member task dan mengirim notifikasi,
+// mention ke user yang bukan member task atau ke penulisnya sendiri diabaikan. Members task harus sudah di-preload
+func (n *notificationService) ProcessMentions(task *domain.Task, authorID uint64, commentID *uint64, source string, text string) ([]*domain.Mention, error) {
+	emails, userIDs := helper.ParseMentions(text)
+	if len(emails) == 0 && len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	mentionedEmails := map[string]bool{}
+	for _, email := range emails {
+		mentionedEmails[email] = true
+	}
+	mentionedUserIDs := map[uint64]bool{}
+	for _, userID := range userIDs {
+		mentionedUserIDs[userID] = true
+	}
+
+	var author string
+	for _, member := range task.Members {
+		if member.UserID == authorID {
+			author = member.Email
+		}
+	}
+
+	var mentions []*domain.Mention
+	for _, member := range task.Members {
+		if member.UserID == authorID {
+			continue
+		}
+		if !mentionedEmails[strings.ToLower(member.Email)] && !mentionedUserIDs[member.UserID] {
+			continue
+		}
+
+		mention := &domain.Mention{
+			TaskID:          task.ID,
+			CommentID:       commentID,
+			Source:          source,
+			MentionedUserID: member.UserID,
+			MentionedByID:   authorID,
+		}
+		created, err := n.notificationRepository.CreateMention(mention)
+		if err != nil {
+			return nil, err
+		}
+		// user yang sudah pernah di-mention pada sumber yang sama tidak dikirimi notifikasi lagi
+		if !created {
+			continue
+		}
+		mentions = append(mentions, mention)
+
+		message := fmt.Sprintf("%s mentioned you in the %s of task: %s", author, strings.ReplaceAll(source, "_", " "), task.NameTask)
+		if err := n.Notify(member.UserID, task.ID, domain.NotificationTypeMention, "You were mentioned", message); err != nil {
+			return nil, err
+		}
+	}
+
+	return mentions, nil
+}
+
+func (n *notificationService) FindAllNotifications(userID uint, unreadOnly bool) ([]*domain.Notification, error) {
+	return n.notificationRepository.FindAllByUser(userID, unreadOnly)
+}
+
+func (n *notificationService) MarkRead(userID uint, notificationID uint) error {
+	return n.notificationRepository.MarkRead(userID, notificationID)
+}
+
+func (n *notificationService) MarkAllRead(userID uint) error {
+	return n.notificationRepository.MarkAllRead(userID)
+}
@@ -3,24 +3,52 @@ package service
 import (
 	"manajemen_tugas_master/model/domain"
 	"manajemen_tugas_master/model/web"
+	"time"
 )
 
 type TaskAndOwnerService interface {
-	CreateTaskAndOwner(user *domain.User, task *domain.Task) (*domain.Task, *domain.Owner, error)
-	GetTaskAndOwnerById(id uint) (*domain.Task, error)
-	FindAllTasksAndOwners() ([]*domain.Task, error)
-	FindAllOwners() ([]*domain.Task, error)
-	FindAllManagers() ([]*domain.Task, error)
-	FindAllEmployees() ([]*domain.Task, error)
-	FindAllPlanningFiles() ([]*domain.Task, error)
-	FindAllProjectFiles() ([]*domain.Task, error)
-	UpdateTaskAndOwner(task *domain.Task, manager *domain.Manager, employee *domain.Employee, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile, taskID uint) (*web.UpdateResponse, error)
-	UpdateValidationOwner(taskID uint, userID uint) error
-	UpdateValidationManager(taskID uint, userID uint) error
-	UpdateValidationEmployee(taskID uint, userID uint) error
-	DeleteManager(taskId uint, managerId uint) error
-	DeleteEmployee(taskId uint, employeeId uint) error
-	DeletePlanningFile(fileId uint) (string, error)
-	DeleteProjectFile(fileId uint) (string, error)
-	DeleteTaskAndOwner(taskID uint) error
+	CreateTaskAndOwner(user *domain.User, task *domain.Task, templateID uint) (*domain.Task, *domain.TaskMember, error)
+	CreateSubtask(user *domain.User, parentID uint, task *domain.Task) (*domain.Task, *domain.TaskMember, error)
+	FindSubtasks(taskID uint, userID uint) ([]*domain.Task, error)
+	ReorderSubtasks(taskID uint, subtaskIDs []uint, userID uint) error
+	FindDependencies(taskID uint, userID uint) ([]*domain.TaskDependency, []*domain.TaskDependency, error)
+	AddDependency(taskID uint, blockedByID uint, userID uint) (*domain.TaskDependency, error)
+	DeleteDependency(taskID uint, blockedByID uint, userID uint) error
+	AttachLabel(taskID uint, labelID uint, userID uint) (*domain.Label, error)
+	DetachLabel(taskID uint, labelID uint, userID uint) error
+	SetCustomFieldValue(taskID uint, fieldID uint, value string, userID uint) (*domain.CustomFieldValue, error)
+	DeleteCustomFieldValue(taskID uint, fieldID uint, userID uint) error
+	CreateChecklistItem(taskID uint, item *domain.ChecklistItem, assignee string, userID uint) (*domain.ChecklistItem, error)
+	FindChecklist(taskID uint, userID uint) ([]*domain.ChecklistItem, error)
+	UpdateChecklistItem(taskID uint, itemID uint, title *string, done *bool, assignee *string, userID uint) (*domain.ChecklistItem, error)
+	ReorderChecklist(taskID uint, itemIDs []uint, userID uint) error
+	DeleteChecklistItem(taskID uint, itemID uint, userID uint) error
+	SetRecurrence(taskID uint, recurrence *domain.TaskRecurrence, userID uint) (*domain.TaskRecurrence, error)
+	DeleteRecurrence(taskID uint, userID uint) error
+	RunDueRecurrences(now time.Time) (int, error)
+	SubmitPlanning(taskID uint, note string, userID uint) (*domain.PlanningApproval, error)
+	ApprovePlanning(taskID uint, note string, userID uint) (*domain.PlanningApproval, error)
+	RejectPlanning(taskID uint, reason string, userID uint) (*domain.PlanningApproval, error)
+	FindPlanningApprovals(taskID uint, userID uint) ([]*domain.PlanningApproval, error)
+	SubmitProjectReview(taskID uint, note string, userID uint) (*domain.ProjectReview, error)
+	AcceptProjectReview(taskID uint, comment string, userID uint) (*domain.ProjectReview, error)
+	RequestProjectChanges(taskID uint, comment string, userID uint) (*domain.ProjectReview, error)
+	FindProjectReviews(taskID uint, userID uint) ([]*domain.ProjectReview, error)
+	FindTaskActivity(taskID uint, userID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error)
+	GetTaskAndOwnerById(id uint, userID uint) (*domain.Task, error)
+	FindAllTasksAndOwners(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error)
+	FindMyTasks(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error)
+	FindAllMembers(userID uint, role string) ([]*domain.Task, error)
+	FindAllPlanningFiles(userID uint) ([]*domain.Task, error)
+	FindAllProjectFiles(userID uint) ([]*domain.Task, error)
+	UpdateTaskAndOwner(task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile, taskID uint, userID uint) (*web.UpdateResponse, error)
+	UpdateValidationRole(taskID uint, userID uint, roles ...string) error
+	UpdateMemberRole(taskID uint, memberID uint, role string, userID uint) (*domain.TaskMember, error)
+	TransferOwnership(taskID uint, email string, userID uint) (*domain.TaskMember, *domain.TaskMember, error)
+	DeleteMember(taskId uint, memberId uint, userID uint) error
+	DeletePlanningFile(taskID uint, fileId uint, userID uint) (string, error)
+	DeleteProjectFile(taskID uint, fileId uint, userID uint) (string, error)
+	DeleteTaskAndOwner(taskID uint, userID uint) error
+	FindAllInvitations(taskID uint) ([]*domain.TaskInvitation, error)
+	DeleteInvitation(taskID uint, invitationID uint, userID uint) error
 }
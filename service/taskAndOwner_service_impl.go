@@ -4,22 +4,33 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
+	"log"
 	"manajemen_tugas_master/helper"
 	"manajemen_tugas_master/model/domain"
 	"manajemen_tugas_master/model/web"
 	"manajemen_tugas_master/repository"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type taskAndOwnerService struct {
 	taskAndOwnerRepository repository.TaskAndOwnerRepository
+	workspaceRepository    repository.WorkspaceRepository
+	userRepository         repository.UserRepository
+	activityRepository     repository.ActivityRepository
+	notificationService    NotificationService
 	validator              *validator.Validate
 }
 
-func NewTaskAndOwnerService(taskAndOwnerRepository repository.TaskAndOwnerRepository, validator *validator.Validate) TaskAndOwnerService {
-	return &taskAndOwnerService{taskAndOwnerRepository, validator}
+func NewTaskAndOwnerService(taskAndOwnerRepository repository.TaskAndOwnerRepository, workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository, activityRepository repository.ActivityRepository, notificationService NotificationService, validator *validator.Validate) TaskAndOwnerService {
+	return &taskAndOwnerService{taskAndOwnerRepository, workspaceRepository, userRepository, activityRepository, notificationService, validator}
 }
 
-func (t *taskAndOwnerService) CreateTaskAndOwner(user *domain.User, task *domain.Task) (*domain.Task, *domain.Owner, error) {
+func (t *taskAndOwnerService) CreateTaskAndOwner(user *domain.User, task *domain.Task, templateID uint) (*domain.Task, *domain.TaskMember, error) {
 	//if err := t.validator.Struct(task); err != nil {
 	//	var errMsg string
 	//	validationErrors := err.(validator.ValidationErrors)
@@ -31,284 +42,1156 @@ func (t *taskAndOwnerService) CreateTaskAndOwner(user *domain.User, task *domain
 	//	return errors.New(errMsg)
 	//}
 
+	// task dari template mengikuti workspace template, nama task boleh dikosongkan
+	var template *domain.TaskTemplate
+	if templateID != 0 {
+		var err error
+		template, err = t.workspaceRepository.FindTaskTemplateById(templateID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if task.WorkspaceID != 0 && task.WorkspaceID != template.WorkspaceID {
+			return nil, nil, errors.New("Template does not belong to this workspace")
+		}
+		task.WorkspaceID = template.WorkspaceID
+		if task.NameTask == "" {
+			task.NameTask = template.NameTask
+		}
+		if task.NameTask == "" {
+			task.NameTask = template.Name
+		}
+	}
+
 	if task.NameTask == "" {
 		return nil, nil, errors.New("Masukkan nama task terlebih dahulu")
 	}
 
-	taskDB, ownerDB, err := t.taskAndOwnerRepository.Create(user, task)
+	// jika workspace tidak dipilih, gunakan satu-satunya workspace milik user
+	if task.WorkspaceID == 0 {
+		workspaces, err := t.workspaceRepository.FindAllByUser(uint(user.ID))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(workspaces) != 1 {
+			return nil, nil, errors.New("Masukkan workspace_id terlebih dahulu")
+		}
+		task.WorkspaceID = workspaces[0].ID
+	}
+	if _, err := t.workspaceRepository.FindMember(uint(task.WorkspaceID), uint(user.ID)); err != nil {
+		return nil, nil, err
+	}
+
+	// isian template disimpan dalam transaksi yang sama, task dibatalkan jika isian template gagal disimpan
+	var taskDB *domain.Task
+	var ownerDB *domain.TaskMember
+	notify := func() {}
+	err := t.taskAndOwnerRepository.Transaction(func(repo repository.TaskAndOwnerRepository) error {
+		var err error
+		taskDB, ownerDB, err = repo.Create(user, task)
+		if err != nil {
+			return err
+		}
+		created := newActivity(taskDB.ID, uint(user.ID), domain.ActivityTaskCreated, "", "", taskDB.NameTask)
+		if template != nil {
+			created.Field = "template"
+			created.NewValue = template.Name
+		}
+		if err := repo.CreateActivities(created); err != nil {
+			return err
+		}
+
+		if template != nil {
+			notify, err = t.applyTaskTemplate(repo, taskDB, template, uint(user.ID))
+		}
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
+	notify()
 
 	return taskDB, ownerDB, nil
 }
 
-func (t *taskAndOwnerService) GetTaskAndOwnerById(id uint) (*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindById(id)
+// applyTaskTemplate mengisi task baru dengan isian template, member dan deskripsi melewati alur update agar
+// undangan email dan mention tetap diproses setelah transaksi selesai
+func (t *taskAndOwnerService) applyTaskTemplate(repo repository.TaskAndOwnerRepository, task *domain.Task, template *domain.TaskTemplate, userID uint) (func(), error) {
+	update := &domain.Task{
+		PlanningDescription: template.PlanningDescription,
+		Priority:            template.Priority,
+	}
+	now := time.Now().UTC()
+	if template.PlanningDueOffsetDays != nil {
+		dueDate := now.AddDate(0, 0, *template.PlanningDueOffsetDays)
+		update.PlanningDueDate = &dueDate
+	}
+	if template.ProjectDueOffsetDays != nil {
+		dueDate := now.AddDate(0, 0, *template.ProjectDueOffsetDays)
+		update.ProjectDueDate = &dueDate
+	}
+
+	var members []*domain.TaskMember
+	for _, email := range template.Managers {
+		members = append(members, &domain.TaskMember{Email: email, Role: domain.TaskRoleManager})
+	}
+	for _, email := range template.Employees {
+		members = append(members, &domain.TaskMember{Email: email, Role: domain.TaskRoleEmployee})
+	}
+
+	_, notify, err := t.updateTask(repo, update, members, &domain.PlanningFile{}, &domain.ProjectFile{}, uint(task.ID), userID)
+	if err != nil {
+		return nil, err
+	}
+	task.PlanningDescription = update.PlanningDescription
+	task.Priority = update.Priority
+	task.PlanningDueDate = update.PlanningDueDate
+	task.ProjectDueDate = update.ProjectDueDate
+
+	// urutan checklist mengikuti urutan pada template
+	for _, title := range template.Checklist {
+		item, err := repo.CreateChecklistItem(&domain.ChecklistItem{TaskID: task.ID, Title: title})
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.CreateActivities(newActivity(task.ID, userID, domain.ActivityChecklistItemAdded, "", "", item.Title)); err != nil {
+			return nil, err
+		}
+		task.Checklist = append(task.Checklist, *item)
+	}
+
+	return notify, nil
 }
 
-func (t *taskAndOwnerService) FindAllTasksAndOwners() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAll()
+func (t *taskAndOwnerService) CreateSubtask(user *domain.User, parentID uint, task *domain.Task) (*domain.Task, *domain.TaskMember, error) {
+	if task.NameTask == "" {
+		return nil, nil, errors.New("Masukkan nama task terlebih dahulu")
+	}
+
+	parent, err := t.taskAndOwnerRepository.FindById(parentID, uint(user.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// subtask hanya bisa dibuat oleh owner atau manager task utama
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(parentID, uint(user.ID), domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return nil, nil, err
+	}
+
+	if task.PlanningDueDate != nil && task.ProjectDueDate != nil && task.PlanningDueDate.After(*task.ProjectDueDate) {
+		return nil, nil, errors.New("Planning due date cannot be after project due date")
+	}
+
+	// subtask berada pada workspace yang sama dengan task utama, pembuat subtask menjadi owner subtask
+	task.ParentID = &parent.ID
+	task.WorkspaceID = parent.WorkspaceID
+
+	var subtask *domain.Task
+	var owner *domain.TaskMember
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		subtask, owner, err = repo.Create(user, task)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{
+			newActivity(subtask.ID, uint(user.ID), domain.ActivityTaskCreated, "parent_id", "", strconv.FormatUint(parent.ID, 10)),
+			newActivity(parent.ID, uint(user.ID), domain.ActivitySubtaskAdded, "", "", subtask.NameTask),
+		}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return subtask, owner, nil
 }
 
-func (t *taskAndOwnerService) FindAllOwners() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAllOwners()
+func (t *taskAndOwnerService) FindSubtasks(taskID uint, userID uint) ([]*domain.Task, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return t.taskAndOwnerRepository.FindSubtasks(taskID)
 }
 
-func (t *taskAndOwnerService) FindAllManagers() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAllManagers()
+func (t *taskAndOwnerService) ReorderSubtasks(taskID uint, subtaskIDs []uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return err
+	}
+
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.ReorderSubtasks(taskID, subtaskIDs); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivitySubtasksReordered, "position", "", formatActivityIDs(subtaskIDs))}, nil
+	})
 }
 
-func (t *taskAndOwnerService) FindAllEmployees() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAllEmployees()
+func (t *taskAndOwnerService) FindDependencies(taskID uint, userID uint) ([]*domain.TaskDependency, []*domain.TaskDependency, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	return t.taskAndOwnerRepository.FindDependencies(taskID)
 }
 
-func (t *taskAndOwnerService) FindAllPlanningFiles() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAllPlanningFiles()
+func (t *taskAndOwnerService) AddDependency(taskID uint, blockedByID uint, userID uint) (*domain.TaskDependency, error) {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return nil, err
+	}
+
+	// task blocker harus bisa diakses oleh user
+	if _, err := t.taskAndOwnerRepository.FindById(blockedByID, userID); err != nil {
+		return nil, err
+	}
+
+	dependency := &domain.TaskDependency{
+		TaskID:      uint64(taskID),
+		BlockedByID: uint64(blockedByID),
+		CreatedByID: uint64(userID),
+	}
+	err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		dependency, err = repo.CreateDependency(dependency)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityDependencyAdded, "blocked_by", "", strconv.FormatUint(uint64(blockedByID), 10))}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dependency, nil
 }
 
-func (t *taskAndOwnerService) FindAllProjectFiles() ([]*domain.Task, error) {
-	return t.taskAndOwnerRepository.FindAllProjectFiles()
+func (t *taskAndOwnerService) DeleteDependency(taskID uint, blockedByID uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return err
+	}
+
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteDependency(taskID, blockedByID); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityDependencyRemoved, "blocked_by", strconv.FormatUint(uint64(blockedByID), 10), "")}, nil
+	})
 }
 
-func (t *taskAndOwnerService) UpdateTaskAndOwner(task *domain.Task, manager *domain.Manager, employee *domain.Employee, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile, taskID uint) (*web.UpdateResponse, error) {
-	taskDB, err := t.taskAndOwnerRepository.FindById(taskID)
+func (t *taskAndOwnerService) AttachLabel(taskID uint, labelID uint, userID uint) (*domain.Label, error) {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return nil, err
+	}
+
+	var label *domain.Label
+	err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		label, err = repo.AttachLabel(taskID, labelID)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityLabelAttached, "label", "", label.Name)}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Update task dengan data dari database
-	task.ID = taskDB.ID
-	task.OwnerID = taskDB.OwnerID
+	return label, nil
+}
 
-	updateTask, updateManager, updateEmployee, updatePlanningFile, updateProjectFile, err := t.taskAndOwnerRepository.Update(task, manager, employee, planningFile, projectFile)
+func (t *taskAndOwnerService) DetachLabel(taskID uint, labelID uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return err
+	}
+
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Persiapan respons
-	response := &web.UpdateResponse{}
+	labelName := strconv.FormatUint(uint64(labelID), 10)
+	for _, label := range task.Labels {
+		if label.ID == uint64(labelID) {
+			labelName = label.Name
+		}
+	}
 
-	// Populate response dengan data dari updateTask
-	response.NameTask = updateTask.NameTask
-	response.PlanningDescription = updateTask.PlanningDescription
-	response.PlanningStatus = updateTask.PlanningStatus
-	response.ProjectStatus = updateTask.ProjectStatus
-	response.PlanningDueDate = updateTask.PlanningDueDate
-	response.ProjectDueDate = updateTask.ProjectDueDate
-	response.Priority = updateTask.Priority
-	response.ProjectComment = updateTask.ProjectComment
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DetachLabel(taskID, labelID); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityLabelDetached, "label", labelName, "")}, nil
+	})
+}
 
-	// Populate managerResponse dengan data dari updateManager jika tidak kosong
-	if updateManager.ID != 0 || updateManager.Email != "" || updateManager.UserID != 0 {
-		response.Manager.ID = updateManager.ID
-		response.Manager.Email = updateManager.Email
-		response.Manager.UserID = updateManager.UserID
+// normalizeCustomFieldValue memvalidasi nilai sesuai tipe custom field dan mengubahnya ke bentuk yang disimpan di database
+func (t *taskAndOwnerService) normalizeCustomFieldValue(field *domain.CustomField, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch field.Type {
+	case domain.CustomFieldTypeText:
+		if len(value) > 1000 {
+			return "", fmt.Errorf("%s must be at most 1000 characters", field.Name)
+		}
+		return value, nil
+	case domain.CustomFieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", field.Name)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case domain.CustomFieldTypeDate:
+		date, err := helper.ParseDueDate(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", field.Name, err)
+		}
+		return date.UTC().Format(time.RFC3339), nil
+	case domain.CustomFieldTypeSelect:
+		for _, option := range field.Options {
+			if option == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of: %s", field.Name, strings.Join(field.Options, ", "))
+	case domain.CustomFieldTypeUser:
+		// user bisa diisi dengan user id atau email, disimpan sebagai user id
+		var user *domain.User
+		var err error
+		if userID, parseErr := strconv.ParseUint(value, 10, 64); parseErr == nil {
+			user, err = t.userRepository.FindById(userID)
+		} else {
+			user, err = t.userRepository.FindByEmail(value)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: User not found", field.Name)
+		}
+		if _, err := t.workspaceRepository.FindMember(uint(field.WorkspaceID), uint(user.ID)); err != nil {
+			return "", fmt.Errorf("%s: User is not a member of the task workspace", field.Name)
+		}
+		return strconv.FormatUint(user.ID, 10), nil
 	}
+	return "", errors.New("Invalid custom field type")
+}
 
-	// Populate employeeResponse dengan data dari updateEmployee jika tidak kosong
-	if updateEmployee.ID != 0 || updateEmployee.Email != "" || updateEmployee.UserID != 0 {
-		response.Employee.ID = updateEmployee.ID
-		response.Employee.Email = updateEmployee.Email
-		response.Employee.UserID = updateEmployee.UserID
+func (t *taskAndOwnerService) SetCustomFieldValue(taskID uint, fieldID uint, value string, userID uint) (*domain.CustomFieldValue, error) {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return nil, err
 	}
 
-	// Populate planningFileResponse dengan data dari updatePlanningFile jika tidak kosong
-	if updatePlanningFile.ID != 0 || updatePlanningFile.FileUrl != "" || updatePlanningFile.FileName != "" {
-		response.PlanningFile.ID = updatePlanningFile.ID
-		response.PlanningFile.FileUrl = updatePlanningFile.FileUrl
-		response.PlanningFile.FileName = updatePlanningFile.FileName
-		bodyText := fmt.Sprintf("planning file uploaded in task :%v", updateTask.NameTask)
-		helper.SetupSES(`land45122@gmail.com`, "planning file", bodyText)
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Populate projectFileResponse dengan data dari updateProjectFile jika tidak kosong
-	if updateProjectFile.ID != 0 || updateProjectFile.FileUrl != "" || updateProjectFile.FileName != "" {
-		response.ProjectFile.ID = updateProjectFile.ID
-		response.ProjectFile.FileUrl = updateProjectFile.FileUrl
-		response.ProjectFile.FileName = updateProjectFile.FileName
+	// custom field harus berasal dari workspace yang sama dengan task
+	field, err := t.workspaceRepository.FindCustomField(uint(task.WorkspaceID), fieldID)
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	normalized, err := t.normalizeCustomFieldValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldValue string
+	for _, value := range task.CustomFields {
+		if value.CustomFieldID == field.ID {
+			oldValue = value.Value
+		}
+	}
+
+	var fieldValue *domain.CustomFieldValue
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		fieldValue, err = repo.SetCustomFieldValue(&domain.CustomFieldValue{
+			TaskID:        task.ID,
+			CustomFieldID: field.ID,
+			Value:         normalized,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if oldValue == normalized {
+			return nil, nil
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, domain.ActivityCustomFieldSet, field.Name, oldValue, normalized)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldValue, nil
 }
 
-func (t *taskAndOwnerService) UpdateValidationOwner(taskID uint, userID uint) error {
-	err := t.taskAndOwnerRepository.UpdateValidationOwner(taskID, userID)
+func (t *taskAndOwnerService) DeleteCustomFieldValue(taskID uint, fieldID uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager); err != nil {
+		return err
+	}
+
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteCustomFieldValue(taskID, fieldID); err != nil {
+			return nil, err
+		}
+		var activities []*domain.TaskActivity
+		for _, value := range task.CustomFields {
+			if value.CustomFieldID == uint64(fieldID) && value.CustomField != nil {
+				activities = append(activities, newActivity(task.ID, userID, domain.ActivityCustomFieldCleared, value.CustomField.Name, value.Value, ""))
+			}
+		}
+		return activities, nil
+	})
 }
 
-func (t *taskAndOwnerService) UpdateValidationManager(taskID uint, userID uint) error {
-	err := t.taskAndOwnerRepository.UpdateValidationManager(taskID, userID)
+// findChecklistAssignee mencari member task berdasarkan user id atau email, assignee checklist harus member task
+func (t *taskAndOwnerService) findChecklistAssignee(task *domain.Task, assignee string) (*uint64, error) {
+	for _, member := range task.Members {
+		if strconv.FormatUint(member.UserID, 10) == assignee || strings.EqualFold(member.Email, assignee) {
+			return &member.UserID, nil
+		}
+	}
+	return nil, errors.New("Assignee must be a member of the task")
+}
+
+func (t *taskAndOwnerService) CreateChecklistItem(taskID uint, item *domain.ChecklistItem, assignee string, userID uint) (*domain.ChecklistItem, error) {
+	item.Title = strings.TrimSpace(item.Title)
+	if item.Title == "" {
+		return nil, errors.New("Checklist title is required")
+	}
+	if len(item.Title) > 255 {
+		return nil, errors.New("Checklist title must be at most 255 characters")
+	}
+
+	// checklist bisa dikelola oleh owner, manager dan employee task
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
+		return nil, err
+	}
+
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if assignee != "" {
+		item.AssigneeID, err = t.findChecklistAssignee(task, assignee)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	item.TaskID = task.ID
+	item.Done = false
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		item, err = repo.CreateChecklistItem(item)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, domain.ActivityChecklistItemAdded, "", "", item.Title)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (t *taskAndOwnerService) FindChecklist(taskID uint, userID uint) ([]*domain.ChecklistItem, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return t.taskAndOwnerRepository.FindChecklist(taskID)
 }
 
-func (t *taskAndOwnerService) UpdateValidationEmployee(taskID uint, userID uint) error {
-	err := t.taskAndOwnerRepository.UpdateValidationEmployee(taskID, userID)
+func (t *taskAndOwnerService) UpdateChecklistItem(taskID uint, itemID uint, title *string, done *bool, assignee *string, userID uint) (*domain.ChecklistItem, error) {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
+		return nil, err
+	}
+
+	item, err := t.taskAndOwnerRepository.FindChecklistItem(taskID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	before := *item
+
+	// hanya field yang dikirim yang diubah
+	if title != nil {
+		item.Title = strings.TrimSpace(*title)
+		if item.Title == "" {
+			return nil, errors.New("Checklist title is required")
+		}
+		if len(item.Title) > 255 {
+			return nil, errors.New("Checklist title must be at most 255 characters")
+		}
+	}
+
+	if done != nil && *done != item.Done {
+		item.Done = *done
+		item.DoneAt = nil
+		if item.Done {
+			now := time.Now()
+			item.DoneAt = &now
+		}
+	}
+
+	// assignee kosong berarti checklist item tidak di-assign ke siapapun
+	if assignee != nil {
+		item.AssigneeID = nil
+		if *assignee != "" {
+			task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+			if err != nil {
+				return nil, err
+			}
+			item.AssigneeID, err = t.findChecklistAssignee(task, *assignee)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		item, err = repo.UpdateChecklistItem(item)
+		if err != nil {
+			return nil, err
+		}
+
+		// perubahan dicatat per field dengan judul item sebagai penanda
+		var activities []*domain.TaskActivity
+		if before.Title != item.Title {
+			activities = append(activities, newActivity(uint64(taskID), userID, domain.ActivityChecklistItemUpdated, "title", before.Title, item.Title))
+		}
+		if before.Done != item.Done {
+			activities = append(activities, newActivity(uint64(taskID), userID, domain.ActivityChecklistItemUpdated, item.Title+": done", strconv.FormatBool(before.Done), strconv.FormatBool(item.Done)))
+		}
+		if formatActivityUserID(before.AssigneeID) != formatActivityUserID(item.AssigneeID) {
+			activities = append(activities, newActivity(uint64(taskID), userID, domain.ActivityChecklistItemUpdated, item.Title+": assignee", formatActivityUserID(before.AssigneeID), formatActivityUserID(item.AssigneeID)))
+		}
+		return activities, nil
+	})
 	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (t *taskAndOwnerService) ReorderChecklist(taskID uint, itemIDs []uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
 		return err
 	}
 
-	return nil
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.ReorderChecklist(taskID, itemIDs); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityChecklistReordered, "position", "", formatActivityIDs(itemIDs))}, nil
+	})
 }
 
-func (t *taskAndOwnerService) DeleteManager(taskId uint, managerId uint) error {
-	db, countEmployee, countPlanningFile, countProjectFile, err := t.taskAndOwnerRepository.DeleteManager(taskId, managerId)
-	if err != nil {
+func (t *taskAndOwnerService) DeleteChecklistItem(taskID uint, itemID uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner, domain.TaskRoleManager, domain.TaskRoleEmployee); err != nil {
 		return err
 	}
 
-	var manager domain.Manager
-	err = helper.ResetAutoIncrement(db, &manager, "id", "managers")
+	item, err := t.taskAndOwnerRepository.FindChecklistItem(taskID, itemID)
 	if err != nil {
 		return err
 	}
 
-	if countEmployee == 1 {
-		var employee domain.Employee
-		err = helper.ResetAutoIncrement(db, &employee, "id", "employees")
-		if err != nil {
-			return err
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteChecklistItem(taskID, itemID); err != nil {
+			return nil, err
 		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityChecklistItemDeleted, "", item.Title, "")}, nil
+	})
+}
+
+func (t *taskAndOwnerService) SetRecurrence(taskID uint, recurrence *domain.TaskRecurrence, userID uint) (*domain.TaskRecurrence, error) {
+	// hanya owner yang dapat mengatur pengulangan task
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner); err != nil {
+		return nil, err
 	}
 
-	if countPlanningFile == 1 {
-		var planningFile domain.PlanningFile
-		err = helper.ResetAutoIncrement(db, &planningFile, "id", "planning_files")
-		if err != nil {
-			return err
-		}
+	switch recurrence.Frequency {
+	case domain.RecurrenceDaily, domain.RecurrenceWeekly, domain.RecurrenceMonthly:
+	default:
+		return nil, errors.New("Frequency must be daily, weekly or monthly")
+	}
+	if recurrence.Interval == 0 {
+		recurrence.Interval = 1
+	}
+	if recurrence.Interval < 0 || recurrence.Interval > 365 {
+		return nil, errors.New("Interval must be between 1 and 365")
+	}
+	if recurrence.Count != nil && *recurrence.Count < 1 {
+		return nil, errors.New("Count must be at least 1")
+	}
+	if recurrence.StartAt.IsZero() {
+		recurrence.StartAt = time.Now().UTC()
+	}
+	if recurrence.Until != nil && recurrence.Until.Before(recurrence.StartAt) {
+		return nil, errors.New("Until cannot be before the start date")
+	}
+
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.RecurrenceSourceID != nil {
+		return nil, errors.New("Recurrence can only be set on the source task")
+	}
+
+	// task sumber adalah occurrence ke-0, salinan pertama dibuat satu periode setelah tanggal mulai
+	recurrence.TaskID = task.ID
+	recurrence.CreatedByID = uint64(userID)
+	recurrence.OccurrenceCount = 0
+	next := recurrence.Occurrence(1)
+	recurrence.NextRunAt = &next
+	if recurrence.Ended(next) {
+		return nil, errors.New("Recurrence would not create any occurrence")
 	}
 
-	if countProjectFile == 1 {
-		var projectFile domain.ProjectFile
-		err = helper.ResetAutoIncrement(db, &projectFile, "id", "project_files")
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		recurrence, err = repo.SaveRecurrence(recurrence)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, domain.ActivityRecurrenceSet, "recurrence", "", formatActivityRecurrence(recurrence))}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return recurrence, nil
 }
 
-func (t *taskAndOwnerService) DeleteEmployee(taskId uint, employeeId uint) error {
-	db, countProjectFile, err := t.taskAndOwnerRepository.DeleteEmployee(taskId, employeeId)
-	if err != nil {
+func (t *taskAndOwnerService) DeleteRecurrence(taskID uint, userID uint) error {
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner); err != nil {
 		return err
 	}
 
-	var employee domain.Employee
-	err = helper.ResetAutoIncrement(db, &employee, "id", "employees")
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteRecurrence(taskID); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityRecurrenceRemoved, "recurrence", "", "")}, nil
+	})
+}
+
+// RunDueRecurrences membuat occurrence untuk semua recurrence yang sudah jatuh tempo, dipanggil oleh scheduler
+func (t *taskAndOwnerService) RunDueRecurrences(now time.Time) (int, error) {
+	ids, err := t.taskAndOwnerRepository.FindDueRecurrenceIDs(now)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if countProjectFile > 0 {
-		var projectFile domain.ProjectFile
-		err = helper.ResetAutoIncrement(db, &projectFile, "id", "project_files")
+	created := 0
+	for _, id := range ids {
+		var task *domain.Task
+		err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+			var err error
+			task, err = repo.RunRecurrence(id, now)
+			if err != nil || task == nil {
+				return nil, err
+			}
+			// occurrence dibuat oleh sistem sehingga tidak memiliki actor
+			return []*domain.TaskActivity{
+				newActivity(task.ID, 0, domain.ActivityTaskCreated, "recurrence_source_id", "", strconv.FormatUint(*task.RecurrenceSourceID, 10)),
+				newActivity(*task.RecurrenceSourceID, 0, domain.ActivityRecurrenceOccurred, "", "", strconv.FormatUint(task.ID, 10)),
+			}, nil
+		})
 		if err != nil {
-			return err
+			// kegagalan satu recurrence tidak menghentikan recurrence lainnya
+			log.Println(err)
+			continue
+		}
+		if task != nil {
+			created++
 		}
 	}
 
-	return nil
+	return created, nil
 }
 
-func (t *taskAndOwnerService) DeletePlanningFile(fileId uint) (string, error) {
-	db, fileName, err := t.taskAndOwnerRepository.DeletePlanningFile(fileId)
-	if err != nil {
-		return "", err
+func (t *taskAndOwnerService) GetTaskAndOwnerById(id uint, userID uint) (*domain.Task, error) {
+	return t.taskAndOwnerRepository.FindById(id, userID)
+}
+
+func (t *taskAndOwnerService) FindAllTasksAndOwners(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error) {
+	if err := t.validator.Struct(filter); err != nil {
+		return nil, 0, fmt.Errorf("Invalid filter: %v", err)
+	}
+	// due date pada filter dinormalisasi ke UTC agar bisa dibandingkan dengan kolom datetime
+	for _, dueDate := range []*string{&filter.PlanningDueFrom, &filter.PlanningDueTo, &filter.ProjectDueFrom, &filter.ProjectDueTo} {
+		if *dueDate == "" {
+			continue
+		}
+		date, err := helper.ParseDueDate(*dueDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		*dueDate = date.UTC().Format("2006-01-02 15:04:05")
+	}
+	filter.LabelIDs = nil
+	for _, labelID := range strings.Split(filter.LabelID, ",") {
+		labelID = strings.TrimSpace(labelID)
+		if labelID == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(labelID, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Invalid label_id %q", labelID)
+		}
+		filter.LabelIDs = append(filter.LabelIDs, id)
+	}
+	// nilai filter custom field dinormalisasi dengan cara yang sama seperti saat disimpan, custom field harus dari workspace user
+	for fieldID, value := range filter.CustomFields {
+		field, err := t.workspaceRepository.FindCustomFieldByUser(userID, uint(fieldID))
+		if err != nil {
+			return nil, 0, err
+		}
+		normalized, err := t.normalizeCustomFieldValue(field, value)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.CustomFields[fieldID] = normalized
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultTaskPageLimit
+	}
+
+	return t.taskAndOwnerRepository.FindAll(userID, filter)
+}
+
+func (t *taskAndOwnerService) FindMyTasks(userID uint, filter *domain.TaskFilter) ([]*domain.Task, int64, error) {
+	// hanya task dimana user menjadi owner, manager atau employee, termasuk subtask
+	filter.MemberID = uint64(userID)
+	filter.IncludeSubtasks = true
+	return t.FindAllTasksAndOwners(userID, filter)
+}
+
+func (t *taskAndOwnerService) FindAllMembers(userID uint, role string) ([]*domain.Task, error) {
+	if role != "" && !domain.IsValidTaskRole(role) {
+		return nil, errors.New("Invalid role")
 	}
+	return t.taskAndOwnerRepository.FindAllMembers(userID, role)
+}
+
+func (t *taskAndOwnerService) FindAllPlanningFiles(userID uint) ([]*domain.Task, error) {
+	return t.taskAndOwnerRepository.FindAllPlanningFiles(userID)
+}
 
-	var planningFile domain.PlanningFile
-	err = helper.ResetAutoIncrement(db, &planningFile, "id", "planning_files")
+func (t *taskAndOwnerService) FindAllProjectFiles(userID uint) ([]*domain.Task, error) {
+	return t.taskAndOwnerRepository.FindAllProjectFiles(userID)
+}
+
+func (t *taskAndOwnerService) UpdateTaskAndOwner(task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile, taskID uint, userID uint) (*web.UpdateResponse, error) {
+	var response *web.UpdateResponse
+	var notify func()
+	err := t.taskAndOwnerRepository.Transaction(func(repo repository.TaskAndOwnerRepository) error {
+		var err error
+		response, notify, err = t.updateTask(repo, task, members, planningFile, projectFile, taskID, userID)
+		return err
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	notify()
 
-	return fileName, nil
+	return response, nil
 }
 
-func (t *taskAndOwnerService) DeleteProjectFile(fileId uint) (string, error) {
-	db, fileName, err := t.taskAndOwnerRepository.DeleteProjectFile(fileId)
+// updateTask menyimpan perubahan task beserta aktivitasnya menggunakan repo dari transaksi pemanggil,
+// email dan mention dikembalikan sebagai fungsi notify agar baru dikirim setelah transaksi berhasil
+func (t *taskAndOwnerService) updateTask(repo repository.TaskAndOwnerRepository, task *domain.Task, members []*domain.TaskMember, planningFile *domain.PlanningFile, projectFile *domain.ProjectFile, taskID uint, userID uint) (*web.UpdateResponse, func(), error) {
+	taskDB, err := repo.FindById(taskID, userID)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+
+	// Update task dengan data dari database, nilai sebelumnya disimpan untuk activity log
+	task.ID = taskDB.ID
+	task.WorkspaceID = taskDB.WorkspaceID
+	before := *taskDB
+
+	// planning due date tidak boleh melewati project due date, due date yang tidak diubah diambil dari database
+	planningDueDate, projectDueDate := taskDB.PlanningDueDate, taskDB.ProjectDueDate
+	if task.PlanningDueDate != nil {
+		planningDueDate = task.PlanningDueDate
+	}
+	if task.ProjectDueDate != nil {
+		projectDueDate = task.ProjectDueDate
+	}
+	if planningDueDate != nil && projectDueDate != nil && planningDueDate.After(*projectDueDate) {
+		return nil, nil, errors.New("Planning due date cannot be after project due date")
+	}
+
+	// perpindahan status mengikuti workflow, file yang diunggah bersamaan ikut dihitung
+	if planningFile.FileUrl != "" {
+		taskDB.PlanningFile = append(taskDB.PlanningFile, *planningFile)
+	}
+	if projectFile.FileUrl != "" {
+		taskDB.ProjectFile = append(taskDB.ProjectFile, *projectFile)
+	}
+	if task.PlanningStatus != "" {
+		// pengajuan dan keputusan planning harus melalui aksi submit, approve atau reject agar tercatat pada riwayat approval
+		switch task.PlanningStatus {
+		case domain.PlanningStatusSubmitted, domain.PlanningStatusApproved, domain.PlanningStatusRejected:
+			return nil, nil, newWorkflowError(http.StatusUnprocessableEntity, "Use the planning submit, approve or reject action to change planning status to %s", task.PlanningStatus)
+		}
+		if err := t.validatePlanningTransition(taskDB, task.PlanningStatus, userID); err != nil {
+			return nil, nil, err
+		}
+		taskDB.PlanningStatus = task.PlanningStatus
+	}
+	if task.ProjectStatus != "" {
+		// review project harus melalui aksi submit, accept atau request changes agar tercatat sebagai putaran review
+		switch task.ProjectStatus {
+		case domain.ProjectStatusInReview, domain.ProjectStatusChangesRequested, domain.ProjectStatusDone:
+			return nil, nil, newWorkflowError(http.StatusUnprocessableEntity, "Use the project review actions to change project status to %s", task.ProjectStatus)
+		}
+		if err := t.validateProjectTransition(taskDB, task.ProjectStatus, userID); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	var projectFile domain.ProjectFile
-	err = helper.ResetAutoIncrement(db, &projectFile, "id", "project_files")
+	// email yang belum terdaftar akan diundang, role diberikan setelah email tersebut signup dan terverifikasi
+	var invitations []*domain.TaskInvitation
+	for _, member := range members {
+		if member == nil || member.Email == "" {
+			continue
+		}
+		if member.Role == domain.TaskRoleOwner || !domain.IsValidTaskRole(member.Role) {
+			return nil, nil, errors.New("Invalid role")
+		}
+
+		invitation, err := t.inviteUnregisteredUser(repo, taskDB, member.Email, member.Role, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if invitation != nil {
+			invitations = append(invitations, invitation)
+			member.Email = ""
+		}
+	}
+
+	updateTask, updateMembers, updatePlanningFile, updateProjectFile, err := repo.Update(task, members, planningFile, projectFile)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
-	return fileName, nil
+	activities := taskFieldActivities(&before, updateTask, userID)
+	for _, member := range updateMembers {
+		activities = append(activities, newActivity(taskDB.ID, userID, domain.ActivityMemberAdded, member.Role, "", member.Email))
+	}
+	for _, invitation := range invitations {
+		activities = append(activities, newActivity(taskDB.ID, userID, domain.ActivityMemberInvited, invitation.Role, "", invitation.Email))
+	}
+	if updatePlanningFile.FileUrl != "" {
+		activities = append(activities, newActivity(taskDB.ID, userID, domain.ActivityFileUploaded, "planning_file", "", updatePlanningFile.FileName))
+	}
+	if updateProjectFile.FileUrl != "" {
+		activities = append(activities, newActivity(taskDB.ID, userID, domain.ActivityFileUploaded, "project_file", "", updateProjectFile.FileName))
+	}
+	if err := repo.CreateActivities(activities...); err != nil {
+		return nil, nil, err
+	}
+
+	// undangan, mention dan email dikirim setelah transaksi berhasil, member yang baru ditambahkan juga bisa di-mention
+	for _, member := range updateMembers {
+		taskDB.Members = append(taskDB.Members, *member)
+	}
+	if updateTask.NameTask != "" {
+		taskDB.NameTask = updateTask.NameTask
+	}
+	notify := func() {
+		for _, invitation := range invitations {
+			t.sendInvitationEmail(taskDB, invitation)
+		}
+		if updateTask.PlanningDescription != "" {
+			if _, err := t.notificationService.ProcessMentions(taskDB, uint64(userID), nil, domain.MentionSourcePlanningDescription, updateTask.PlanningDescription); err != nil {
+				log.Println(err)
+			}
+		}
+		if updatePlanningFile.ID != 0 || updatePlanningFile.FileUrl != "" || updatePlanningFile.FileName != "" {
+			bodyText := fmt.Sprintf("planning file uploaded in task :%v", updateTask.NameTask)
+			helper.SetupSES(`land45122@gmail.com`, "planning file", bodyText)
+		}
+	}
+
+	// Persiapan respons
+	response := &web.UpdateResponse{}
+
+	// Populate response dengan data dari updateTask
+	response.NameTask = updateTask.NameTask
+	response.PlanningDescription = updateTask.PlanningDescription
+	response.PlanningStatus = updateTask.PlanningStatus
+	response.ProjectStatus = updateTask.ProjectStatus
+	response.PlanningDueDate = updateTask.PlanningDueDate
+	response.ProjectDueDate = updateTask.ProjectDueDate
+	response.Priority = updateTask.Priority
+	response.Invitations = invitations
+
+	// Populate member response, manager dan employee tetap diisi agar response lama tidak berubah
+	response.Members = updateMembers
+	for _, member := range updateMembers {
+		switch member.Role {
+		case domain.TaskRoleManager:
+			response.Manager.ID = member.ID
+			response.Manager.Email = member.Email
+			response.Manager.UserID = member.UserID
+		case domain.TaskRoleEmployee:
+			response.Employee.ID = member.ID
+			response.Employee.Email = member.Email
+			response.Employee.UserID = member.UserID
+		}
+	}
+
+	// Populate planningFileResponse dengan data dari updatePlanningFile jika tidak kosong
+	if updatePlanningFile.ID != 0 || updatePlanningFile.FileUrl != "" || updatePlanningFile.FileName != "" {
+		response.PlanningFile.ID = updatePlanningFile.ID
+		response.PlanningFile.FileUrl = updatePlanningFile.FileUrl
+		response.PlanningFile.FileName = updatePlanningFile.FileName
+	}
+
+	// Populate projectFileResponse dengan data dari updateProjectFile jika tidak kosong
+	if updateProjectFile.ID != 0 || updateProjectFile.FileUrl != "" || updateProjectFile.FileName != "" {
+		response.ProjectFile.ID = updateProjectFile.ID
+		response.ProjectFile.FileUrl = updateProjectFile.FileUrl
+		response.ProjectFile.FileName = updateProjectFile.FileName
+	}
+
+	return response, notify, nil
 }
 
-func (t *taskAndOwnerService) DeleteTaskAndOwner(taskID uint) error {
-	db, countOwners, countManager, countEmployee, countPlanningFile, countProjectFile, err := t.taskAndOwnerRepository.Delete(taskID)
+// inviteUnregisteredUser membuat undangan jika email belum terdaftar, mengembalikan nil jika email sudah terdaftar
+func (t *taskAndOwnerService) inviteUnregisteredUser(repo repository.TaskAndOwnerRepository, task *domain.Task, email string, role string, userID uint) (*domain.TaskInvitation, error) {
+	if err := t.validator.Var(email, "required,email"); err != nil {
+		return nil, errors.New("Invalid format in Email")
+	}
+	if _, err := t.userRepository.FindByEmail(email); err == nil {
+		return nil, nil
+	}
+
+	return repo.CreateInvitation(&domain.TaskInvitation{
+		TaskID:      task.ID,
+		Email:       email,
+		Role:        role,
+		InvitedByID: uint64(userID),
+	})
+}
+
+// sendInvitationEmail mengirim email undangan, kegagalan pengiriman tidak membatalkan undangan yang sudah tersimpan
+func (t *taskAndOwnerService) sendInvitationEmail(task *domain.Task, invitation *domain.TaskInvitation) {
+	bodyText := fmt.Sprintf("You have been invited as %s in task: %v\n\n"+
+		"Sign up with this email to join the task:\n%s/user/signup?email=%s", invitation.Role, task.NameTask, os.Getenv("APP_URL"), url.QueryEscape(invitation.Email))
+	if err := helper.SetupSES(invitation.Email, "Task invitation", bodyText); err != nil {
+		log.Println(err)
+	}
+}
+
+func (t *taskAndOwnerService) FindAllInvitations(taskID uint) ([]*domain.TaskInvitation, error) {
+	return t.taskAndOwnerRepository.FindAllInvitations(taskID)
+}
+
+func (t *taskAndOwnerService) DeleteInvitation(taskID uint, invitationID uint, userID uint) error {
+	invitations, err := t.taskAndOwnerRepository.FindAllInvitations(taskID)
 	if err != nil {
 		return err
 	}
-	if err == nil {
-		err = helper.SetupS3DeleteAll()
-		if err != nil {
-			return err
+
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteInvitation(taskID, invitationID); err != nil {
+			return nil, err
 		}
+		var activities []*domain.TaskActivity
+		for _, invitation := range invitations {
+			if invitation.ID == uint64(invitationID) {
+				activities = append(activities, newActivity(uint64(taskID), userID, domain.ActivityInvitationDeleted, invitation.Role, invitation.Email, ""))
+			}
+		}
+		return activities, nil
+	})
+}
+
+func (t *taskAndOwnerService) UpdateValidationRole(taskID uint, userID uint, roles ...string) error {
+	err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, roles...)
+	if err != nil {
+		return err
 	}
 
-	// reset auto increment
-	if countOwners > 0 {
-		var owner domain.Owner
-		err = helper.ResetAutoIncrement(db, &owner, "id", "owners")
+	return nil
+}
+
+func (t *taskAndOwnerService) UpdateMemberRole(taskID uint, memberID uint, role string, userID uint) (*domain.TaskMember, error) {
+	if !domain.IsValidTaskRole(role) {
+		return nil, errors.New("Invalid role")
+	}
+	if role == domain.TaskRoleOwner {
+		return nil, errors.New("Use transfer ownership to change the owner")
+	}
+
+	// hanya owner yang dapat mengubah role member
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner); err != nil {
+		return nil, err
+	}
+
+	memberDB, err := t.taskAndOwnerRepository.FindMember(taskID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	oldRole := memberDB.Role
+
+	var member *domain.TaskMember
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		member, err = repo.UpdateMemberRole(taskID, memberID, role)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if oldRole == member.Role {
+			return nil, nil
 		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityMemberRoleChanged, member.Email, oldRole, member.Role)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (t *taskAndOwnerService) TransferOwnership(taskID uint, email string, userID uint) (*domain.TaskMember, *domain.TaskMember, error) {
+	if err := t.validator.Var(email, "required,email"); err != nil {
+		return nil, nil, errors.New("Invalid email")
 	}
 
-	if countManager > 0 {
-		var manager domain.Manager
-		err = helper.ResetAutoIncrement(db, &manager, "id", "managers")
+	// hanya owner yang dapat memindahkan kepemilikan task
+	if err := t.taskAndOwnerRepository.UpdateValidationRole(taskID, userID, domain.TaskRoleOwner); err != nil {
+		return nil, nil, err
+	}
+
+	var oldOwner, newOwner *domain.TaskMember
+	err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		oldOwner, newOwner, err = repo.TransferOwnership(taskID, email)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityOwnershipTransferred, domain.TaskRoleOwner, oldOwner.Email, newOwner.Email)}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oldOwner, newOwner, nil
+}
+
+func (t *taskAndOwnerService) DeleteMember(taskId uint, memberId uint, userID uint) error {
+	member, err := t.taskAndOwnerRepository.FindMember(taskId, memberId)
+	if err != nil {
+		return err
+	}
+
+	// employee bisa dihapus oleh manager atau owner, role lainnya hanya bisa dihapus oleh owner
+	switch member.Role {
+	case domain.TaskRoleOwner:
+		return errors.New("Owner cannot be deleted")
+	case domain.TaskRoleEmployee:
+		err = t.taskAndOwnerRepository.UpdateValidationRole(taskId, userID, domain.TaskRoleOwner, domain.TaskRoleManager)
+	default:
+		err = t.taskAndOwnerRepository.UpdateValidationRole(taskId, userID, domain.TaskRoleOwner)
+	}
+	if err != nil {
+		return err
 	}
 
-	if countEmployee > 0 {
-		var employee domain.Employee
-		err = helper.ResetAutoIncrement(db, &employee, "id", "employees")
+	return t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		if err := repo.DeleteMember(taskId, memberId); err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(uint64(taskId), userID, domain.ActivityMemberRemoved, member.Role, member.Email, "")}, nil
+	})
+}
+
+func (t *taskAndOwnerService) DeletePlanningFile(taskID uint, fileId uint, userID uint) (string, error) {
+	var fileName string
+	err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		fileName, err = repo.DeletePlanningFile(taskID, fileId)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityFileDeleted, "planning_file", fileName, "")}, nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	if countPlanningFile > 0 {
-		var planningFile domain.PlanningFile
-		err = helper.ResetAutoIncrement(db, &planningFile, "id", "planning_files")
+	return fileName, nil
+}
+
+func (t *taskAndOwnerService) DeleteProjectFile(taskID uint, fileId uint, userID uint) (string, error) {
+	var fileName string
+	err := t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		// file yang sudah diajukan pada putaran review tetap disimpan sebagai riwayat putaran tersebut
+		round, err := repo.FindProjectFileReviewRound(taskID, fileId)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if round > 0 {
+			return nil, newWorkflowError(http.StatusConflict, "Project file was submitted in review round %d and cannot be deleted", round)
 		}
-	}
 
-	if countProjectFile > 0 {
-		var projectFile domain.ProjectFile
-		err = helper.ResetAutoIncrement(db, &projectFile, "id", "project_files")
+		fileName, err = repo.DeleteProjectFile(taskID, fileId)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		return []*domain.TaskActivity{newActivity(uint64(taskID), userID, domain.ActivityFileDeleted, "project_file", fileName, "")}, nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	var task domain.Task
-	err = helper.ResetAutoIncrement(db, &task, "id", "tasks")
+	return fileName, nil
+}
+
+func (t *taskAndOwnerService) DeleteTaskAndOwner(taskID uint, userID uint) error {
+	taskDB, err := t.taskAndOwnerRepository.FindById(taskID, userID)
 	if err != nil {
 		return err
 	}
 
+	var fileNames []string
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		fileNames, err = repo.Delete(taskID)
+		if err != nil {
+			return nil, err
+		}
+		// activity task yang dihapus tetap disimpan agar tetap muncul pada feed workspace
+		deleted := newActivity(taskDB.ID, userID, domain.ActivityTaskDeleted, "", taskDB.NameTask, "")
+		deleted.WorkspaceID = taskDB.WorkspaceID
+		return []*domain.TaskActivity{deleted}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// hanya file milik task dan subtask-nya yang dihapus dari S3, kegagalan hanya dicatat karena datanya sudah terhapus
+	for _, fileName := range fileNames {
+		if err := helper.SetupS3Delete(fileName); err != nil {
+			log.Printf("Failed to delete task file %s: %v", fileName, err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"manajemen_tugas_master/model/domain"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCustomFieldValue(t *testing.T) {
+	textField := &domain.CustomField{Name: "Catatan", Type: domain.CustomFieldTypeText}
+	numberField := &domain.CustomField{Name: "Budget", Type: domain.CustomFieldTypeNumber}
+	dateField := &domain.CustomField{Name: "Deadline", Type: domain.CustomFieldTypeDate}
+	selectField := &domain.CustomField{Name: "Prioritas", Type: domain.CustomFieldTypeSelect, Options: []string{"low", "high"}}
+	unknownField := &domain.CustomField{Name: "Lainnya", Type: "checkbox"}
+
+	tests := []struct {
+		name    string
+		field   *domain.CustomField
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"text is trimmed", textField, "  catatan rapat  ", "catatan rapat", false},
+		{"text at limit", textField, strings.Repeat("a", 1000), strings.Repeat("a", 1000), false},
+		{"text too long", textField, strings.Repeat("a", 1001), "", true},
+		{"integer number", numberField, "42", "42", false},
+		{"decimal number", numberField, " 1500.50 ", "1500.5", false},
+		{"scientific number", numberField, "1e3", "1000", false},
+		{"invalid number", numberField, "seribu", "", true},
+		{"date only", dateField, "2024-05-01", "2024-05-01T00:00:00Z", false},
+		{"date with offset stored as utc", dateField, "2024-05-01T17:00:00+07:00", "2024-05-01T10:00:00Z", false},
+		{"invalid date", dateField, "01/05/2024", "", true},
+		{"valid option", selectField, "high", "high", false},
+		{"option is trimmed", selectField, " low ", "low", false},
+		{"option is case sensitive", selectField, "High", "", true},
+		{"unknown option", selectField, "urgent", "", true},
+		{"unknown field type", unknownField, "true", "", true},
+	}
+
+	service := &taskAndOwnerService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.normalizeCustomFieldValue(tt.field, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
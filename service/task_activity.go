@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// newActivity membuat catatan aktivitas task, actorID 0 berarti perubahan dilakukan oleh sistem
+func newActivity(taskID uint64, actorID uint, action string, field string, oldValue string, newValue string) *domain.TaskActivity {
+	activity := &domain.TaskActivity{
+		TaskID:   taskID,
+		Action:   action,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}
+	if actorID != 0 {
+		actor := uint64(actorID)
+		activity.ActorID = &actor
+	}
+	return activity
+}
+
+// withActivity menjalankan perubahan dan menyimpan aktivitas yang dihasilkan dalam satu transaksi,
+// jika aktivitas gagal disimpan perubahan ikut dibatalkan agar riwayat task tidak pernah terlewat
+func (t *taskAndOwnerService) withActivity(fn func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error)) error {
+	return t.taskAndOwnerRepository.Transaction(func(repo repository.TaskAndOwnerRepository) error {
+		activities, err := fn(repo)
+		if err != nil {
+			return err
+		}
+		return repo.CreateActivities(activities...)
+	})
+}
+
+// taskFieldActivities membandingkan field task yang dikirim dengan nilai sebelumnya, field yang tidak berubah tidak dicatat
+func taskFieldActivities(before *domain.Task, update *domain.Task, actorID uint) []*domain.TaskActivity {
+	changes := []struct {
+		field    string
+		oldValue string
+		newValue string
+		updated  bool
+	}{
+		{"name_task", before.NameTask, update.NameTask, update.NameTask != ""},
+		{"planning_description", before.PlanningDescription, update.PlanningDescription, update.PlanningDescription != ""},
+		{"planning_status", before.PlanningStatus, update.PlanningStatus, update.PlanningStatus != ""},
+		{"project_status", before.ProjectStatus, update.ProjectStatus, update.ProjectStatus != ""},
+		{"planning_due_date", formatActivityTime(before.PlanningDueDate), formatActivityTime(update.PlanningDueDate), update.PlanningDueDate != nil},
+		{"project_due_date", formatActivityTime(before.ProjectDueDate), formatActivityTime(update.ProjectDueDate), update.ProjectDueDate != nil},
+		{"priority", before.Priority, update.Priority, update.Priority != ""},
+	}
+
+	var activities []*domain.TaskActivity
+	for _, change := range changes {
+		if !change.updated || change.oldValue == change.newValue {
+			continue
+		}
+		activities = append(activities, newActivity(before.ID, actorID, domain.ActivityTaskUpdated, change.field, change.oldValue, change.newValue))
+	}
+	return activities
+}
+
+func formatActivityTime(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.UTC().Format(time.RFC3339)
+}
+
+func formatActivityUserID(userID *uint64) string {
+	if userID == nil {
+		return ""
+	}
+	return strconv.FormatUint(*userID, 10)
+}
+
+func formatActivityRecurrence(recurrence *domain.TaskRecurrence) string {
+	value := fmt.Sprintf("every %d %s", recurrence.Interval, recurrence.Frequency)
+	if recurrence.Count != nil {
+		value += fmt.Sprintf(", %d times", *recurrence.Count)
+	}
+	if recurrence.Until != nil {
+		value += ", until " + formatActivityTime(recurrence.Until)
+	}
+	return value
+}
+
+func formatActivityIDs(ids []uint) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(values, ",")
+}
+
+// normalizeActivityFilter memvalidasi filter aktivitas dan mengisi nilai default pagination
+func normalizeActivityFilter(validate *validator.Validate, filter *domain.ActivityFilter) error {
+	if err := validate.Struct(filter); err != nil {
+		return fmt.Errorf("Invalid filter: %v", err)
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultActivityPageLimit
+	}
+	return nil
+}
+
+func (t *taskAndOwnerService) FindTaskActivity(taskID uint, userID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, 0, err
+	}
+	if err := normalizeActivityFilter(t.validator, filter); err != nil {
+		return nil, 0, err
+	}
+
+	return t.activityRepository.FindAllByTask(taskID, filter)
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/repository"
+	"net/http"
+	"strings"
+)
+
+// WorkflowError adalah error perpindahan status, Status berisi HTTP status code yang sesuai
+type WorkflowError struct {
+	Status  int
+	Message string
+}
+
+func (e *WorkflowError) Error() string {
+	return e.Message
+}
+
+func newWorkflowError(status int, format string, args ...interface{}) *WorkflowError {
+	return &WorkflowError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// validateTransition mengecek status tujuan, transisi dari status sekarang dan role user yang memindahkan
+func validateTransition(transitions domain.StatusTransitions, name string, from string, to string, role string) error {
+	if !transitions.IsValidStatus(to) {
+		return newWorkflowError(http.StatusUnprocessableEntity, "Invalid %s status %q", name, to)
+	}
+	if from == to {
+		return newWorkflowError(http.StatusConflict, "Task %s status is already %s", name, to)
+	}
+
+	roles, ok := transitions.Roles(from, to)
+	if !ok {
+		return newWorkflowError(http.StatusConflict, "Cannot change %s status from %s to %s", name, from, to)
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return nil
+		}
+	}
+	return newWorkflowError(http.StatusForbidden, "Only %s can change %s status from %s to %s", strings.Join(roles, " or "), name, from, to)
+}
+
+// validatePlanningTransition mengecek perpindahan status planning, planning hanya bisa diajukan jika sudah ada planning file
+func (t *taskAndOwnerService) validatePlanningTransition(task *domain.Task, to string, userID uint) error {
+	from := task.CurrentPlanningStatus()
+	if err := validateTransition(domain.PlanningTransitions, "planning", from, to, task.MemberRole(uint64(userID))); err != nil {
+		return err
+	}
+
+	switch to {
+	case domain.PlanningStatusSubmitted:
+		if len(task.PlanningFile) == 0 {
+			return newWorkflowError(http.StatusUnprocessableEntity, "Upload a planning file before submitting the planning")
+		}
+	case domain.PlanningStatusNotApproved:
+		// planning yang sudah approved hanya bisa dibuka kembali selama project belum dikerjakan
+		if task.CurrentProjectStatus() != domain.ProjectStatusUndone {
+			return newWorkflowError(http.StatusConflict, "Cannot reopen the planning while the project is %s", task.CurrentProjectStatus())
+		}
+	}
+	return nil
+}
+
+// validateProjectTransition mengecek perpindahan status project, project hanya bisa dikerjakan setelah planning approved
+// dan semua task blocker sudah done
+func (t *taskAndOwnerService) validateProjectTransition(task *domain.Task, to string, userID uint) error {
+	from := task.CurrentProjectStatus()
+	if err := validateTransition(domain.ProjectTransitions, "project", from, to, task.MemberRole(uint64(userID))); err != nil {
+		return err
+	}
+
+	switch to {
+	case domain.ProjectStatusWorking:
+		if task.CurrentPlanningStatus() != domain.PlanningStatusApproved {
+			return newWorkflowError(http.StatusConflict, "Planning must be approved before the project can start")
+		}
+		count, err := t.taskAndOwnerRepository.CountUnfinishedBlockers(uint(task.ID))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return newWorkflowError(http.StatusConflict, "Task is blocked by %d unfinished task(s)", count)
+		}
+	case domain.ProjectStatusInReview:
+		if len(task.ProjectFile) == 0 {
+			return newWorkflowError(http.StatusUnprocessableEntity, "Upload a project file before submitting the project for review")
+		}
+	}
+	return nil
+}
+
+func (t *taskAndOwnerService) SubmitPlanning(taskID uint, note string, userID uint) (*domain.PlanningApproval, error) {
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validatePlanningTransition(task, domain.PlanningStatusSubmitted, userID); err != nil {
+		return nil, err
+	}
+
+	submittedByID := uint64(userID)
+	var approval *domain.PlanningApproval
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		approval, err = repo.SubmitPlanning(&domain.PlanningApproval{
+			TaskID:        task.ID,
+			SubmittedByID: &submittedByID,
+			Note:          strings.TrimSpace(note),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, domain.ActivityPlanningSubmitted, "planning_status", task.PlanningStatus, domain.PlanningStatusSubmitted)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// owner diberi tahu bahwa ada planning yang menunggu keputusan
+	message := fmt.Sprintf("Planning for task %s has been submitted for approval (round %d)", task.NameTask, approval.Round)
+	t.notifyMembers(task, domain.TaskRoleOwner, userID, domain.NotificationTypePlanning, "Planning submitted", message)
+
+	return approval, nil
+}
+
+func (t *taskAndOwnerService) ApprovePlanning(taskID uint, note string, userID uint) (*domain.PlanningApproval, error) {
+	return t.decidePlanning(taskID, domain.PlanningStatusApproved, strings.TrimSpace(note), userID)
+}
+
+func (t *taskAndOwnerService) RejectPlanning(taskID uint, reason string, userID uint) (*domain.PlanningApproval, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, newWorkflowError(http.StatusUnprocessableEntity, "Reason is required to reject the planning")
+	}
+
+	return t.decidePlanning(taskID, domain.PlanningStatusRejected, reason, userID)
+}
+
+// decidePlanning menyimpan keputusan owner lalu memberi tahu manager task
+func (t *taskAndOwnerService) decidePlanning(taskID uint, status string, reason string, userID uint) (*domain.PlanningApproval, error) {
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validatePlanningTransition(task, status, userID); err != nil {
+		return nil, err
+	}
+
+	action := domain.ActivityPlanningApproved
+	if status == domain.PlanningStatusRejected {
+		action = domain.ActivityPlanningRejected
+	}
+	var approval *domain.PlanningApproval
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		approval, err = repo.DecidePlanning(taskID, status, userID, reason)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, action, "planning_status", task.PlanningStatus, status)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Planning for task %s has been %s", task.NameTask, status)
+	if reason != "" {
+		message += fmt.Sprintf(": %s", reason)
+	}
+	t.notifyMembers(task, domain.TaskRoleManager, userID, domain.NotificationTypePlanning, "Planning "+status, message)
+
+	return approval, nil
+}
+
+func (t *taskAndOwnerService) FindPlanningApprovals(taskID uint, userID uint) ([]*domain.PlanningApproval, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return t.taskAndOwnerRepository.FindPlanningApprovals(taskID)
+}
+
+// notifyMembers mengirim notifikasi ke semua member dengan role tertentu kecuali user yang melakukan aksi,
+// kegagalan notifikasi hanya dicatat pada log
+func (t *taskAndOwnerService) notifyMembers(task *domain.Task, role string, actorID uint, notificationType string, subject string, message string) {
+	for _, member := range task.MembersByRole(role) {
+		if member.UserID == uint64(actorID) {
+			continue
+		}
+		if err := t.notificationService.Notify(member.UserID, task.ID, notificationType, subject, message); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (t *taskAndOwnerService) SubmitProjectReview(taskID uint, note string, userID uint) (*domain.ProjectReview, error) {
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateProjectTransition(task, domain.ProjectStatusInReview, userID); err != nil {
+		return nil, err
+	}
+
+	// setiap putaran harus membawa file baru, file putaran sebelumnya tetap tercatat pada putaran tersebut
+	files, err := t.taskAndOwnerRepository.FindUnreviewedProjectFiles(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, newWorkflowError(http.StatusUnprocessableEntity, "Upload a new project file before submitting the project for review")
+	}
+
+	submittedByID := uint64(userID)
+	var review *domain.ProjectReview
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		review, err = repo.SubmitProjectReview(&domain.ProjectReview{
+			TaskID:        task.ID,
+			SubmittedByID: &submittedByID,
+			Note:          strings.TrimSpace(note),
+			Files:         files,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, domain.ActivityReviewSubmitted, "project_status", task.ProjectStatus, domain.ProjectStatusInReview)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Project for task %s has been submitted for review (round %d)", task.NameTask, review.Round)
+	t.notifyMembers(task, domain.TaskRoleManager, userID, domain.NotificationTypeReview, "Project submitted for review", message)
+
+	return review, nil
+}
+
+func (t *taskAndOwnerService) AcceptProjectReview(taskID uint, comment string, userID uint) (*domain.ProjectReview, error) {
+	return t.decideProjectReview(taskID, domain.ProjectReviewAccepted, domain.ProjectStatusDone, strings.TrimSpace(comment), userID)
+}
+
+func (t *taskAndOwnerService) RequestProjectChanges(taskID uint, comment string, userID uint) (*domain.ProjectReview, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, newWorkflowError(http.StatusUnprocessableEntity, "Comment is required to request changes")
+	}
+
+	return t.decideProjectReview(taskID, domain.ProjectReviewChangesRequested, domain.ProjectStatusChangesRequested, comment, userID)
+}
+
+// decideProjectReview menyimpan hasil review lalu memberi tahu employee task
+func (t *taskAndOwnerService) decideProjectReview(taskID uint, status string, projectStatus string, comment string, userID uint) (*domain.ProjectReview, error) {
+	task, err := t.taskAndOwnerRepository.FindById(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateProjectTransition(task, projectStatus, userID); err != nil {
+		return nil, err
+	}
+
+	action := domain.ActivityReviewAccepted
+	if status == domain.ProjectReviewChangesRequested {
+		action = domain.ActivityReviewChangesRequested
+	}
+	var review *domain.ProjectReview
+	err = t.withActivity(func(repo repository.TaskAndOwnerRepository) ([]*domain.TaskActivity, error) {
+		var err error
+		review, err = repo.DecideProjectReview(taskID, status, userID, comment, projectStatus)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.TaskActivity{newActivity(task.ID, userID, action, "project_status", task.ProjectStatus, projectStatus)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Project review round %d for task %s: %s", review.Round, task.NameTask, strings.ReplaceAll(status, "_", " "))
+	if comment != "" {
+		message += fmt.Sprintf("\n\n%s", comment)
+	}
+	t.notifyMembers(task, domain.TaskRoleEmployee, userID, domain.NotificationTypeReview, "Project review", message)
+
+	return review, nil
+}
+
+func (t *taskAndOwnerService) FindProjectReviews(taskID uint, userID uint) ([]*domain.ProjectReview, error) {
+	if _, err := t.taskAndOwnerRepository.FindById(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	return t.taskAndOwnerRepository.FindProjectReviews(taskID)
+}
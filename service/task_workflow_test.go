@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"manajemen_tugas_master/model/domain"
+	"net/http"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name        string
+		transitions domain.StatusTransitions
+		from        string
+		to          string
+		role        string
+		wantStatus  int
+	}{
+		// planning
+		{"manager submits planning", domain.PlanningTransitions, domain.PlanningStatusNotApproved, domain.PlanningStatusSubmitted, domain.TaskRoleManager, 0},
+		{"employee cannot submit planning", domain.PlanningTransitions, domain.PlanningStatusNotApproved, domain.PlanningStatusSubmitted, domain.TaskRoleEmployee, http.StatusForbidden},
+		{"owner approves planning", domain.PlanningTransitions, domain.PlanningStatusSubmitted, domain.PlanningStatusApproved, domain.TaskRoleOwner, 0},
+		{"owner rejects planning", domain.PlanningTransitions, domain.PlanningStatusSubmitted, domain.PlanningStatusRejected, domain.TaskRoleOwner, 0},
+		{"manager cannot approve planning", domain.PlanningTransitions, domain.PlanningStatusSubmitted, domain.PlanningStatusApproved, domain.TaskRoleManager, http.StatusForbidden},
+		{"manager resubmits rejected planning", domain.PlanningTransitions, domain.PlanningStatusRejected, domain.PlanningStatusSubmitted, domain.TaskRoleManager, 0},
+		{"owner reopens approved planning", domain.PlanningTransitions, domain.PlanningStatusApproved, domain.PlanningStatusNotApproved, domain.TaskRoleOwner, 0},
+		{"planning cannot skip submission", domain.PlanningTransitions, domain.PlanningStatusNotApproved, domain.PlanningStatusApproved, domain.TaskRoleOwner, http.StatusConflict},
+		{"rejected planning cannot be approved", domain.PlanningTransitions, domain.PlanningStatusRejected, domain.PlanningStatusApproved, domain.TaskRoleOwner, http.StatusConflict},
+		{"planning already submitted", domain.PlanningTransitions, domain.PlanningStatusSubmitted, domain.PlanningStatusSubmitted, domain.TaskRoleManager, http.StatusConflict},
+		{"unknown planning status", domain.PlanningTransitions, domain.PlanningStatusNotApproved, "done", domain.TaskRoleOwner, http.StatusUnprocessableEntity},
+		{"member without role", domain.PlanningTransitions, domain.PlanningStatusNotApproved, domain.PlanningStatusSubmitted, "", http.StatusForbidden},
+
+		// project
+		{"employee starts working", domain.ProjectTransitions, domain.ProjectStatusUndone, domain.ProjectStatusWorking, domain.TaskRoleEmployee, 0},
+		{"owner starts working", domain.ProjectTransitions, domain.ProjectStatusUndone, domain.ProjectStatusWorking, domain.TaskRoleOwner, 0},
+		{"employee submits for review", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusInReview, domain.TaskRoleEmployee, 0},
+		{"manager cannot submit for review", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusInReview, domain.TaskRoleManager, http.StatusForbidden},
+		{"manager stops working", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusUndone, domain.TaskRoleManager, 0},
+		{"employee cannot stop working", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusUndone, domain.TaskRoleEmployee, http.StatusForbidden},
+		{"manager accepts review", domain.ProjectTransitions, domain.ProjectStatusInReview, domain.ProjectStatusDone, domain.TaskRoleManager, 0},
+		{"owner requests changes", domain.ProjectTransitions, domain.ProjectStatusInReview, domain.ProjectStatusChangesRequested, domain.TaskRoleOwner, 0},
+		{"employee cannot accept review", domain.ProjectTransitions, domain.ProjectStatusInReview, domain.ProjectStatusDone, domain.TaskRoleEmployee, http.StatusForbidden},
+		{"employee resumes after changes requested", domain.ProjectTransitions, domain.ProjectStatusChangesRequested, domain.ProjectStatusWorking, domain.TaskRoleEmployee, 0},
+		{"employee resubmits after changes requested", domain.ProjectTransitions, domain.ProjectStatusChangesRequested, domain.ProjectStatusInReview, domain.TaskRoleEmployee, 0},
+		{"owner reopens done project", domain.ProjectTransitions, domain.ProjectStatusDone, domain.ProjectStatusWorking, domain.TaskRoleOwner, 0},
+		{"manager cannot reopen done project", domain.ProjectTransitions, domain.ProjectStatusDone, domain.ProjectStatusWorking, domain.TaskRoleManager, http.StatusForbidden},
+		{"project cannot skip review", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusDone, domain.TaskRoleOwner, http.StatusConflict},
+		{"undone project cannot be done", domain.ProjectTransitions, domain.ProjectStatusUndone, domain.ProjectStatusDone, domain.TaskRoleOwner, http.StatusConflict},
+		{"project already working", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.ProjectStatusWorking, domain.TaskRoleOwner, http.StatusConflict},
+		{"unknown project status", domain.ProjectTransitions, domain.ProjectStatusWorking, domain.PlanningStatusApproved, domain.TaskRoleOwner, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTransition(tt.transitions, "test", tt.from, tt.to, tt.role)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("expected transition to be allowed, got %v", err)
+				}
+				return
+			}
+
+			var workflowErr *WorkflowError
+			if !errors.As(err, &workflowErr) {
+				t.Fatalf("expected WorkflowError with status %d, got %v", tt.wantStatus, err)
+			}
+			if workflowErr.Status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.wantStatus, workflowErr.Status, workflowErr.Message)
+			}
+		})
+	}
+}
+
+func TestStatusTransitionsTargetsAreValid(t *testing.T) {
+	tables := map[string]domain.StatusTransitions{
+		"planning": domain.PlanningTransitions,
+		"project":  domain.ProjectTransitions,
+	}
+
+	for name, transitions := range tables {
+		for from, targets := range transitions {
+			for to, roles := range targets {
+				if !transitions.IsValidStatus(to) {
+					t.Errorf("%s: transition %s -> %s targets an unknown status", name, from, to)
+				}
+				if len(roles) == 0 {
+					t.Errorf("%s: transition %s -> %s has no allowed role", name, from, to)
+				}
+				for _, role := range roles {
+					if !domain.IsValidTaskRole(role) {
+						t.Errorf("%s: transition %s -> %s allows unknown role %q", name, from, to, role)
+					}
+				}
+			}
+		}
+	}
+}
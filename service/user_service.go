@@ -1,13 +1,23 @@
 package service
 
-import "manajemen_tugas_master/model/domain"
+import (
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/model/web"
+)
 
 type UserService interface {
 	SignupUser(user *domain.User) (*domain.User, error)
-	LoginUser(user *domain.User) (string, error)
+	LoginUser(user *domain.User) (*web.TokenResponse, error)
+	RefreshToken(refreshToken string) (*web.TokenResponse, error)
+	LogoutUser(user *domain.User, accessToken string, refreshToken string, allSessions bool) error
 	RequireAuthUser(tokenString string) (*domain.User, error)
 	GetUserByID(id interface{}) (*domain.User, error)
 	FindAllUsers() ([]*domain.User, error)
 	UpdateUser(user *domain.User) (*domain.User, error)
+	UpdateUserRole(actor *domain.User, userID uint, role string) (*domain.User, error)
 	DeleteUser(id uint) error
+	ForgotPassword(email string) error
+	ResetPassword(token string, password string) error
+	VerifyEmail(token string) (*domain.User, error)
+	ResendVerification(email string) error
 }
@@ -6,22 +6,39 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"log"
 	"manajemen_tugas_master/helper"
 	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/model/web"
 	"manajemen_tugas_master/repository"
 	"os"
 	"time"
 )
 
+const (
+	accessTokenDuration   = time.Minute * 15
+	refreshTokenDuration  = time.Hour * 24 * 30
+	passwordResetDuration = time.Hour
+	verificationDuration  = time.Hour * 24
+)
+
+// error untuk membedakan penyebab gagalnya autentikasi pada middleware
+var (
+	ErrTokenExpired   = errors.New("Token expired")
+	ErrTokenMalformed = errors.New("Invalid token")
+	ErrTokenRevoked   = errors.New("Token has been revoked")
+)
+
 type userService struct {
-	userRepository repository.UserRepository
-	validator      *validator.Validate
+	userRepository         repository.UserRepository
+	taskAndOwnerRepository repository.TaskAndOwnerRepository
+	validator              *validator.Validate
 }
 
 // NewUserService menggabungkan userService dan Userservice untuk membuat instance UserService baru,
 // yang memiliki kemampuan UserRepository dan validate
-func NewUserService(userRepository repository.UserRepository, validator *validator.Validate) UserService {
-	return &userService{userRepository, validator}
+func NewUserService(userRepository repository.UserRepository, taskAndOwnerRepository repository.TaskAndOwnerRepository, validator *validator.Validate) UserService {
+	return &userService{userRepository, taskAndOwnerRepository, validator}
 }
 
 func (s *userService) SignupUser(user *domain.User) (*domain.User, error) {
@@ -48,50 +65,157 @@ func (s *userService) SignupUser(user *domain.User) (*domain.User, error) {
 		return nil, err
 	}
 
+	// user tetap terdaftar walaupun email gagal terkirim, verifikasi bisa dikirim ulang
+	if err := s.sendVerificationEmail(signup); err != nil {
+		log.Println(err)
+	}
+
 	return signup, nil
 }
 
-func (s *userService) LoginUser(user *domain.User) (string, error) {
+func (s *userService) LoginUser(user *domain.User) (*web.TokenResponse, error) {
 	if err := s.validator.Struct(user); err != nil {
 		var errMsg string
 		validationErrors := err.(validator.ValidationErrors)
 		for _, fieldError := range validationErrors {
 			errMsg += fmt.Sprintf("Invalid format in %s", fieldError.Field())
 		}
-		return "", errors.New(errMsg)
+		return nil, errors.New(errMsg)
 	}
-	if user.Password != "" {
-		return "", errors.New("Password is required") // Mengembalikan pesan kesalahan jika login gagal
+	if user.Password == "" {
+		return nil, errors.New("Password is required") // Mengembalikan pesan kesalahan jika login gagal
 	}
 
 	// Mendapatkan data pengguna dari repository
 	userRepo := *user
 	dbUser, err := s.userRepository.Login(&userRepo)
 	if err != nil {
-		return "", errors.New("User not found") // Mengembalikan pesan kesalahan jika login gagal
+		return nil, errors.New("User not found") // Mengembalikan pesan kesalahan jika login gagal
 	}
 
 	// membandingkan hash password di database, dengan hash password yang baru di kirimkan
 	err = bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(user.Password))
 	if err != nil {
-		return "", errors.New("Invalid password") // Mengembalikan pesan kesalahan jika password salah
+		return nil, errors.New("Invalid password") // Mengembalikan pesan kesalahan jika password salah
 	}
 
-	// generate token jwt
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": dbUser.ID,
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(),
-	})
+	if !dbUser.Verified {
+		return nil, errors.New("Please verify your email before logging in")
+	}
+
+	return s.generateTokens(dbUser)
+}
+
+func (s *userService) RefreshToken(refreshToken string) (*web.TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, errors.New("Refresh token is required")
+	}
 
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	dbToken, err := s.userRepository.FindRefreshToken(helper.HashToken(refreshToken))
 	if err != nil {
-		return "", err // Return empty token string and error
+		return nil, errors.New("Invalid refresh token")
+	}
+
+	// refresh token yang sudah pernah dipakai berarti kemungkinan bocor, cabut semua sesi milik user tersebut
+	if dbToken.RevokedAt != nil {
+		if err := s.userRepository.RevokeAllRefreshTokens(dbToken.UserID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("Refresh token has been revoked, please login again")
+	}
+	if time.Now().After(dbToken.ExpiresAt) {
+		return nil, errors.New("Refresh token expired, please login again")
+	}
+
+	dbUser, err := s.userRepository.FindById(dbToken.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("User not found: %v", err)
 	}
 
-	return tokenString, nil // Return token string dan nil error
+	// rotasi refresh token, token lama tidak bisa dipakai lagi.
+	// jika token sudah dicabut oleh request lain yang bersamaan, perlakukan sebagai pemakaian ulang
+	revoked, err := s.userRepository.RevokeRefreshToken(dbToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		if err := s.userRepository.RevokeAllRefreshTokens(dbToken.UserID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenRevoked
+	}
+
+	return s.generateTokens(dbUser)
+}
+
+func (s *userService) LogoutUser(user *domain.User, accessToken string, refreshToken string, allSessions bool) error {
+	claims, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	// access token dimasukkan ke daftar revoked sampai waktu kadaluarsanya
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	if jti != "" {
+		if err := s.userRepository.RevokeAccessToken(jti, time.Unix(int64(exp), 0)); err != nil {
+			return err
+		}
+	}
+
+	if allSessions {
+		return s.userRepository.RevokeAllRefreshTokens(user.ID)
+	}
+
+	if refreshToken != "" {
+		dbToken, err := s.userRepository.FindRefreshToken(helper.HashToken(refreshToken))
+		if err == nil && dbToken.UserID == user.ID {
+			if _, err := s.userRepository.RevokeRefreshToken(dbToken.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 func (s *userService) RequireAuthUser(tokenString string) (*domain.User, error) {
+	claims, err := s.parseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, ErrTokenMalformed
+	}
+	if time.Now().Unix() > int64(exp) {
+		return nil, ErrTokenExpired // Mengembalikan error jika token telah kadaluarsa
+	}
+
+	// Memastikan token belum di-logout
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, ErrTokenMalformed
+	}
+	revoked, err := s.userRepository.IsAccessTokenRevoked(jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	// Find the user with token sub
+	userID := claims["sub"]
+	user, err := s.userRepository.FindById(userID)
+	if err != nil {
+		return nil, fmt.Errorf("User not found: %v", err)
+	}
+
+	return user, nil
+}
+
+func (s *userService) parseAccessToken(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -100,26 +224,63 @@ func (s *userService) RequireAuthUser(tokenString string) (*domain.User, error)
 		return []byte(os.Getenv("SECRET")), nil
 	})
 	if err != nil {
-		return nil, err // Mengembalikan error jika terjadi kesalahan saat mem-parse token
+		// Mengembalikan error jika terjadi kesalahan saat mem-parse token
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
 	}
 
 	// Memastikan token adalah token yang valid
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		return nil, errors.New("Invalid token") // Mengembalikan error jika token tidak valid
+		return nil, ErrTokenMalformed // Mengembalikan error jika token tidak valid
 	}
-	if time.Now().Unix() > int64(claims["exp"].(float64)) {
-		return nil, errors.New("Token expired") // Mengembalikan error jika token telah kadaluarsa
+
+	return claims, nil
+}
+
+// generateTokens membuat access token jwt berumur pendek dan refresh token yang disimpan di database
+func (s *userService) generateTokens(user *domain.User) (*web.TokenResponse, error) {
+	jti, err := helper.GenerateRandomToken()
+	if err != nil {
+		return nil, err
 	}
 
-	// Find the user with token sub
-	userID := claims["sub"]
-	user, err := s.userRepository.FindById(userID)
+	accessTokenExpiresAt := time.Now().Add(accessTokenDuration)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": user.ID,
+		"jti": jti,
+		"iat": time.Now().Unix(),
+		"exp": accessTokenExpiresAt.Unix(),
+	})
+
+	accessToken, err := token.SignedString([]byte(os.Getenv("SECRET")))
 	if err != nil {
-		return nil, fmt.Errorf("User not found: %v", err)
+		return nil, err
 	}
 
-	return user, nil
+	refreshToken, err := helper.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenExpiresAt := time.Now().Add(refreshTokenDuration)
+	_, err = s.userRepository.CreateRefreshToken(&domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: refreshTokenExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &web.TokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenExpiresAt,
+	}, nil
 }
 
 func (s *userService) GetUserByID(id interface{}) (*domain.User, error) {
@@ -142,29 +303,206 @@ func (s *userService) UpdateUser(user *domain.User) (*domain.User, error) {
 		return nil, errors.New(errMsg)
 	}
 
-	updateUser, err := s.userRepository.Update(user)
+	// ambil data user dari database agar field lain (password, status verifikasi) tidak ikut tertimpa
+	dbUser, err := s.userRepository.FindById(user.ID)
 	if err != nil {
 		return nil, errors.New("User not found")
 	}
 
+	// email baru harus diverifikasi ulang
+	emailChanged := user.Email != "" && user.Email != dbUser.Email
+	if emailChanged {
+		dbUser.Email = user.Email
+		dbUser.Verified = false
+		dbUser.VerifiedAt = nil
+	}
+
+	updateUser, err := s.userRepository.Update(dbUser)
+	if err != nil {
+		return nil, errors.New("User not found")
+	}
+
+	if emailChanged {
+		// token verifikasi yang dikirim ke email lama tidak boleh dipakai lagi
+		if err := s.userRepository.DeleteUnusedEmailVerifications(updateUser.ID); err != nil {
+			return nil, err
+		}
+		if err := s.sendVerificationEmail(updateUser); err != nil {
+			log.Println(err)
+		}
+	}
+
 	return updateUser, nil
 }
 
+func (s *userService) UpdateUserRole(actor *domain.User, userID uint, role string) (*domain.User, error) {
+	if role != domain.UserRoleAdmin && role != domain.UserRoleUser {
+		return nil, errors.New("Role must be admin or user")
+	}
+	// admin tidak bisa mengubah role dirinya sendiri, agar sistem tidak kehilangan admin
+	if actor.ID == uint64(userID) {
+		return nil, errors.New("You cannot change your own role")
+	}
+
+	dbUser, err := s.userRepository.FindById(userID)
+	if err != nil {
+		return nil, errors.New("User not found")
+	}
+
+	dbUser.Role = role
+	return s.userRepository.Update(dbUser)
+}
+
 func (s *userService) DeleteUser(id uint) error {
 	if id == 0 {
 		return errors.New("Invalid user ID")
 	}
 
-	db, err := s.userRepository.Delete(id)
+	// auto increment users tidak di-reset, agar id user yang dihapus tidak dipakai ulang oleh user baru
+	return s.userRepository.Delete(id)
+}
+
+func (s *userService) ForgotPassword(email string) error {
+	if err := s.validator.Var(email, "required,email"); err != nil {
+		return errors.New("Invalid format in Email")
+	}
+
+	// email yang tidak terdaftar tidak dianggap error, agar tidak bisa dipakai untuk mengecek email yang terdaftar
+	user, err := s.userRepository.FindByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	// kegagalan setelah email ditemukan hanya dicatat pada log, respons harus sama dengan email yang tidak terdaftar
+	token, err := helper.GenerateRandomToken()
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	_, err = s.userRepository.CreatePasswordReset(&domain.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetDuration),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	bodyText := fmt.Sprintf("We received a request to reset your password.\n\n"+
+		"Open the following link to choose a new password, the link is valid for %v and can only be used once:\n%s/user/password/reset?token=%s\n\n"+
+		"If you did not request a password reset, you can ignore this email.", passwordResetDuration, os.Getenv("APP_URL"), token)
+	if err := helper.SetupSES(user.Email, "Reset your password", bodyText); err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *userService) ResetPassword(token string, password string) error {
+	if token == "" {
+		return errors.New("Reset token is required")
+	}
+	if err := s.validator.Var(password, "required,min=8"); err != nil {
+		return errors.New("Password must be at least 8 characters")
+	}
+
+	passwordReset, err := s.userRepository.FindPasswordReset(helper.HashToken(token))
+	if err != nil {
+		return errors.New("Invalid password reset token")
+	}
+	if passwordReset.UsedAt != nil {
+		return errors.New("Password reset token has already been used")
+	}
+	if time.Now().After(passwordReset.ExpiresAt) {
+		return errors.New("Password reset token expired")
+	}
+
+	// Password diubah menjadi hash menggunakan algoritma bcrypt
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return errors.New("Failed to hash password")
+	}
+
+	return s.userRepository.ResetPassword(passwordReset, string(hash))
+}
+
+func (s *userService) VerifyEmail(token string) (*domain.User, error) {
+	if token == "" {
+		return nil, errors.New("Verification token is required")
+	}
+
+	emailVerification, err := s.userRepository.FindEmailVerification(helper.HashToken(token))
+	if err != nil {
+		return nil, errors.New("Invalid verification token")
+	}
+	if emailVerification.UsedAt != nil {
+		return nil, errors.New("Email verification token has already been used")
+	}
+	if time.Now().After(emailVerification.ExpiresAt) {
+		return nil, errors.New("Verification token expired, Please request a new one")
+	}
+
+	// token harus dikirim ke email user yang sekarang, agar undangan email lain tidak ikut diterima
+	dbUser, err := s.userRepository.FindById(emailVerification.UserID)
+	if err != nil || dbUser.Email != emailVerification.Email {
+		return nil, errors.New("Invalid verification token")
+	}
+
+	user, err := s.userRepository.VerifyEmail(emailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	// undangan task untuk email ini langsung diterima setelah email terverifikasi
+	if _, err := s.taskAndOwnerRepository.AcceptInvitations(user); err != nil {
+		log.Println(err)
+	}
+
+	return user, nil
+}
+
+func (s *userService) ResendVerification(email string) error {
+	if err := s.validator.Var(email, "required,email"); err != nil {
+		return errors.New("Invalid format in Email")
+	}
+
+	// sama seperti lupa password, email yang tidak terdaftar atau sudah terverifikasi tidak dianggap error
+	user, err := s.userRepository.FindByEmail(email)
+	if err != nil || user.Verified {
+		return nil
+	}
+
+	// kegagalan mengirim email hanya dicatat pada log, respons harus sama dengan email yang tidak terdaftar
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *userService) sendVerificationEmail(user *domain.User) error {
+	token, err := helper.GenerateRandomToken()
 	if err != nil {
 		return err
 	}
 
-	var user *domain.User
-	err = helper.ResetAutoIncrement(db, &user, "id", "users")
+	_, err = s.userRepository.CreateEmailVerification(&domain.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(verificationDuration),
+	})
 	if err != nil {
 		return err
 	}
 
+	bodyText := fmt.Sprintf("Welcome to Manajemen Tugas.\n\n"+
+		"Open the following link to verify your email, the link is valid for %v:\n%s/user/verify?token=%s", verificationDuration, os.Getenv("APP_URL"), token)
+	if err := helper.SetupSES(user.Email, "Verify your email", bodyText); err != nil {
+		return fmt.Errorf("Failed to send verification email: %v", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,28 @@
+package service
+
+import "manajemen_tugas_master/model/domain"
+
+type WorkspaceService interface {
+	CreateWorkspace(user *domain.User, workspace *domain.Workspace) (*domain.Workspace, error)
+	GetWorkspaceById(id uint, userID uint) (*domain.Workspace, error)
+	FindAllWorkspaces(userID uint) ([]*domain.Workspace, error)
+	UpdateWorkspace(workspace *domain.Workspace, userID uint) (*domain.Workspace, error)
+	AddMember(workspaceID uint, userID uint, email string, role string) (*domain.WorkspaceMember, error)
+	DeleteMember(workspaceID uint, userID uint, memberUserID uint) error
+	CreateLabel(workspaceID uint, userID uint, label *domain.Label) (*domain.Label, error)
+	FindAllLabels(workspaceID uint, userID uint) ([]*domain.Label, error)
+	UpdateLabel(workspaceID uint, userID uint, label *domain.Label) (*domain.Label, error)
+	DeleteLabel(workspaceID uint, userID uint, labelID uint) error
+	CreateCustomField(workspaceID uint, userID uint, field *domain.CustomField) (*domain.CustomField, error)
+	FindAllCustomFields(workspaceID uint, userID uint) ([]*domain.CustomField, error)
+	UpdateCustomField(workspaceID uint, userID uint, field *domain.CustomField) (*domain.CustomField, error)
+	DeleteCustomField(workspaceID uint, userID uint, fieldID uint) error
+	CreateTaskTemplate(workspaceID uint, userID uint, template *domain.TaskTemplate) (*domain.TaskTemplate, error)
+	FindAllTaskTemplates(workspaceID uint, userID uint) ([]*domain.TaskTemplate, error)
+	FindTaskTemplate(workspaceID uint, userID uint, templateID uint) (*domain.TaskTemplate, error)
+	UpdateTaskTemplate(workspaceID uint, userID uint, template *domain.TaskTemplate) (*domain.TaskTemplate, error)
+	DeleteTaskTemplate(workspaceID uint, userID uint, templateID uint) error
+	FindActivity(workspaceID uint, userID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error)
+	ValidationMember(workspaceID uint, userID uint) error
+	ValidationAdmin(workspaceID uint, userID uint) error
+}
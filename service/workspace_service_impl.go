@@ -0,0 +1,393 @@
+package service
+
+import (
+	"errors"
+	"manajemen_tugas_master/model/domain"
+	"manajemen_tugas_master/repository"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type workspaceService struct {
+	workspaceRepository repository.WorkspaceRepository
+	userRepository      repository.UserRepository
+	activityRepository  repository.ActivityRepository
+	validator           *validator.Validate
+}
+
+func NewWorkspaceService(workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository, activityRepository repository.ActivityRepository, validator *validator.Validate) WorkspaceService {
+	return &workspaceService{workspaceRepository, userRepository, activityRepository, validator}
+}
+
+func (w *workspaceService) CreateWorkspace(user *domain.User, workspace *domain.Workspace) (*domain.Workspace, error) {
+	if workspace.Name == "" {
+		return nil, errors.New("Workspace name is required")
+	}
+
+	return w.workspaceRepository.Create(user, workspace)
+}
+
+func (w *workspaceService) GetWorkspaceById(id uint, userID uint) (*domain.Workspace, error) {
+	if err := w.ValidationMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.FindById(id)
+}
+
+func (w *workspaceService) FindAllWorkspaces(userID uint) ([]*domain.Workspace, error) {
+	return w.workspaceRepository.FindAllByUser(userID)
+}
+
+func (w *workspaceService) UpdateWorkspace(workspace *domain.Workspace, userID uint) (*domain.Workspace, error) {
+	if workspace.Name == "" {
+		return nil, errors.New("Workspace name is required")
+	}
+	if err := w.ValidationAdmin(uint(workspace.ID), userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.Update(workspace)
+}
+
+func (w *workspaceService) AddMember(workspaceID uint, userID uint, email string, role string) (*domain.WorkspaceMember, error) {
+	if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		role = domain.WorkspaceRoleMember
+	}
+	if role != domain.WorkspaceRoleAdmin && role != domain.WorkspaceRoleMember {
+		return nil, errors.New("Role must be admin or member")
+	}
+
+	if err := w.validator.Var(email, "required,email"); err != nil {
+		return nil, errors.New("Invalid format in Email")
+	}
+
+	user, err := w.userRepository.FindByEmail(email)
+	if err != nil {
+		return nil, errors.New("User not found")
+	}
+	if !user.Verified {
+		return nil, errors.New("User has not verified their email")
+	}
+
+	return w.workspaceRepository.AddMember(&domain.WorkspaceMember{
+		WorkspaceID: uint64(workspaceID),
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        role,
+	})
+}
+
+func (w *workspaceService) DeleteMember(workspaceID uint, userID uint, memberUserID uint) error {
+	// member boleh keluar sendiri dari workspace, selain itu hanya admin yang boleh menghapus member
+	if userID != memberUserID {
+		if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+			return err
+		}
+	}
+
+	return w.workspaceRepository.DeleteMember(workspaceID, memberUserID)
+}
+
+// validateLabel memastikan nama label diisi dan warna berformat hex, contoh: #ff0000
+func (w *workspaceService) validateLabel(label *domain.Label) error {
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" {
+		return errors.New("Label name is required")
+	}
+	if len(label.Name) > 50 {
+		return errors.New("Label name must be at most 50 characters")
+	}
+	if label.Color == "" {
+		label.Color = domain.DefaultLabelColor
+	}
+	if err := w.validator.Var(label.Color, "hexcolor,len=7"); err != nil {
+		return errors.New("Label color must be a hex color, e.g. #ff0000")
+	}
+	label.Color = strings.ToLower(label.Color)
+	return nil
+}
+
+func (w *workspaceService) CreateLabel(workspaceID uint, userID uint, label *domain.Label) (*domain.Label, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := w.validateLabel(label); err != nil {
+		return nil, err
+	}
+
+	label.WorkspaceID = uint64(workspaceID)
+	return w.workspaceRepository.CreateLabel(label)
+}
+
+func (w *workspaceService) FindAllLabels(workspaceID uint, userID uint) ([]*domain.Label, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.FindAllLabels(workspaceID)
+}
+
+func (w *workspaceService) UpdateLabel(workspaceID uint, userID uint, label *domain.Label) (*domain.Label, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	labelDB, err := w.workspaceRepository.FindLabel(workspaceID, uint(label.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// field yang kosong tidak diubah
+	if label.Name == "" {
+		label.Name = labelDB.Name
+	}
+	if label.Color == "" {
+		label.Color = labelDB.Color
+	}
+	if err := w.validateLabel(label); err != nil {
+		return nil, err
+	}
+
+	labelDB.Name = label.Name
+	labelDB.Color = label.Color
+	return w.workspaceRepository.UpdateLabel(labelDB)
+}
+
+func (w *workspaceService) DeleteLabel(workspaceID uint, userID uint, labelID uint) error {
+	// label yang mungkin sudah dipakai banyak task hanya bisa dihapus oleh admin workspace
+	if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+		return err
+	}
+
+	label, err := w.workspaceRepository.FindLabel(workspaceID, labelID)
+	if err != nil {
+		return err
+	}
+
+	return w.workspaceRepository.DeleteLabel(label)
+}
+
+// validateCustomField memastikan nama dan tipe custom field valid, tipe select wajib memiliki pilihan
+func validateCustomField(field *domain.CustomField) error {
+	field.Name = strings.TrimSpace(field.Name)
+	if field.Name == "" {
+		return errors.New("Custom field name is required")
+	}
+	if len(field.Name) > 100 {
+		return errors.New("Custom field name must be at most 100 characters")
+	}
+	if !domain.IsValidCustomFieldType(field.Type) {
+		return errors.New("Custom field type must be text, number, date, select or user")
+	}
+
+	var options []string
+	for _, option := range field.Options {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	field.Options = options
+	if field.Type == domain.CustomFieldTypeSelect && len(field.Options) == 0 {
+		return errors.New("Custom field with type select must have options")
+	}
+	if field.Type != domain.CustomFieldTypeSelect {
+		field.Options = nil
+	}
+	return nil
+}
+
+func (w *workspaceService) CreateCustomField(workspaceID uint, userID uint, field *domain.CustomField) (*domain.CustomField, error) {
+	// definisi custom field hanya bisa dikelola oleh admin workspace
+	if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := validateCustomField(field); err != nil {
+		return nil, err
+	}
+
+	field.WorkspaceID = uint64(workspaceID)
+	return w.workspaceRepository.CreateCustomField(field)
+}
+
+func (w *workspaceService) FindAllCustomFields(workspaceID uint, userID uint) ([]*domain.CustomField, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.FindAllCustomFields(workspaceID)
+}
+
+func (w *workspaceService) UpdateCustomField(workspaceID uint, userID uint, field *domain.CustomField) (*domain.CustomField, error) {
+	if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	fieldDB, err := w.workspaceRepository.FindCustomField(workspaceID, uint(field.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// field yang kosong tidak diubah
+	if field.Name != "" {
+		fieldDB.Name = field.Name
+	}
+	if field.Options != nil {
+		fieldDB.Options = field.Options
+	}
+	if err := validateCustomField(fieldDB); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.UpdateCustomField(fieldDB)
+}
+
+func (w *workspaceService) DeleteCustomField(workspaceID uint, userID uint, fieldID uint) error {
+	if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+		return err
+	}
+
+	field, err := w.workspaceRepository.FindCustomField(workspaceID, fieldID)
+	if err != nil {
+		return err
+	}
+
+	return w.workspaceRepository.DeleteCustomField(field)
+}
+
+// FindActivity mengambil aktivitas semua task pada workspace, termasuk task yang sudah dihapus
+func (w *workspaceService) FindActivity(workspaceID uint, userID uint, filter *domain.ActivityFilter) ([]*domain.TaskActivity, int64, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, 0, err
+	}
+	if err := normalizeActivityFilter(w.validator, filter); err != nil {
+		return nil, 0, err
+	}
+
+	return w.activityRepository.FindAllByWorkspace(workspaceID, filter)
+}
+
+func (w *workspaceService) ValidationMember(workspaceID uint, userID uint) error {
+	_, err := w.workspaceRepository.FindMember(workspaceID, userID)
+	return err
+}
+
+func (w *workspaceService) ValidationAdmin(workspaceID uint, userID uint) error {
+	member, err := w.workspaceRepository.FindMember(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != domain.WorkspaceRoleAdmin {
+		return errors.New("Only for workspace admin")
+	}
+
+	return nil
+}
+
+// validateTaskTemplate memastikan isian template valid sebelum disimpan, template yang valid bisa langsung dipakai membuat task
+func (w *workspaceService) validateTaskTemplate(template *domain.TaskTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" {
+		return errors.New("Template name is required")
+	}
+	if len(template.Name) > 100 {
+		return errors.New("Template name must be at most 100 characters")
+	}
+	if template.Priority != "" && template.Priority != "high" && template.Priority != "medium" && template.Priority != "low" {
+		return errors.New("Priority must be high, medium or low")
+	}
+
+	for _, emails := range [][]string{template.Managers, template.Employees} {
+		for _, email := range emails {
+			if err := w.validator.Var(email, "required,email"); err != nil {
+				return errors.New("Invalid format in Email")
+			}
+		}
+	}
+	for _, title := range template.Checklist {
+		if title == "" || len(title) > 255 {
+			return errors.New("Checklist item title must be between 1 and 255 characters")
+		}
+	}
+
+	if template.PlanningDueOffsetDays != nil && *template.PlanningDueOffsetDays < 0 {
+		return errors.New("Planning due offset cannot be negative")
+	}
+	if template.ProjectDueOffsetDays != nil && *template.ProjectDueOffsetDays < 0 {
+		return errors.New("Project due offset cannot be negative")
+	}
+	if template.PlanningDueOffsetDays != nil && template.ProjectDueOffsetDays != nil && *template.PlanningDueOffsetDays > *template.ProjectDueOffsetDays {
+		return errors.New("Planning due date cannot be after project due date")
+	}
+	return nil
+}
+
+func (w *workspaceService) CreateTaskTemplate(workspaceID uint, userID uint, template *domain.TaskTemplate) (*domain.TaskTemplate, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := w.validateTaskTemplate(template); err != nil {
+		return nil, err
+	}
+
+	template.WorkspaceID = uint64(workspaceID)
+	template.CreatedByID = uint64(userID)
+	return w.workspaceRepository.CreateTaskTemplate(template)
+}
+
+func (w *workspaceService) FindAllTaskTemplates(workspaceID uint, userID uint) ([]*domain.TaskTemplate, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.FindAllTaskTemplates(workspaceID)
+}
+
+func (w *workspaceService) FindTaskTemplate(workspaceID uint, userID uint, templateID uint) (*domain.TaskTemplate, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.FindTaskTemplate(workspaceID, templateID)
+}
+
+func (w *workspaceService) UpdateTaskTemplate(workspaceID uint, userID uint, template *domain.TaskTemplate) (*domain.TaskTemplate, error) {
+	if err := w.ValidationMember(workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	templateDB, err := w.workspaceRepository.FindTaskTemplate(workspaceID, uint(template.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// isi template diganti seluruhnya, pembuat template tidak berubah
+	template.WorkspaceID = templateDB.WorkspaceID
+	template.CreatedByID = templateDB.CreatedByID
+	if err := w.validateTaskTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return w.workspaceRepository.UpdateTaskTemplate(template)
+}
+
+func (w *workspaceService) DeleteTaskTemplate(workspaceID uint, userID uint, templateID uint) error {
+	template, err := w.workspaceRepository.FindTaskTemplate(workspaceID, templateID)
+	if err != nil {
+		return err
+	}
+
+	// template hanya bisa dihapus oleh pembuatnya atau admin workspace
+	if template.CreatedByID != uint64(userID) {
+		if err := w.ValidationAdmin(workspaceID, userID); err != nil {
+			return err
+		}
+	}
+
+	return w.workspaceRepository.DeleteTaskTemplate(template)
+}